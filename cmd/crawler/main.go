@@ -7,21 +7,40 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"sort"
+	"strings"
 	"time"
 
 	"rank-vision/internal/crawler"
+	appconfig "rank-vision/pkg/config"
 )
 
 const defaultUserAgent = "RankVision Bot/1.0 (+https://rank-vision.com; bot@rank-vision.com) Compatible/Go-http-client/1.1"
 
 func main() {
+	defaults := appconfig.NewConfig().Crawler
+
 	// Парсим аргументы командной строки
 	url := flag.String("url", "", "URL для краулинга")
 	timeout := flag.Duration("timeout", 30*time.Second, "Таймаут запроса")
+	requestDelay := flag.Duration("request-delay", time.Duration(defaults.RequestDelay)*time.Millisecond, "Задержка между запросами к одному хосту")
 	userAgent := flag.String("user-agent", defaultUserAgent, "User-Agent для запросов")
 	maxPages := flag.Int("max-pages", 100, "Максимальное количество страниц для краулинга")
 	maxDepth := flag.Int("max-depth", 3, "Максимальная глубина краулинга")
 	singlePage := flag.Bool("single", false, "Краулить только одну страницу")
+	ignoreRobots := flag.Bool("ignore-robots", false, "Игнорировать правила robots.txt")
+	ignoreSitemaps := flag.Bool("ignore-sitemaps", false, "Не загружать sitemap")
+	frontierDir := flag.String("frontier-dir", "", "Каталог для очереди URL на диске (по умолчанию очередь в памяти)")
+	workers := flag.Int("workers", defaults.MaxConcurrentRequests, "Количество параллельных воркеров")
+	maxConnsPerHost := flag.Int("max-conns-per-host", defaults.MaxConnsPerHost, "Максимальное количество параллельных запросов к одному хосту")
+	maxQueueSize := flag.Int("max-queue-size", defaults.MaxQueueSize, "Максимальное количество URL в очереди (0 — без ограничения)")
+	maxBodySize := flag.Int64("max-body-size", 10<<20, "Максимальный размер тела ответа в байтах")
+	headResources := flag.Bool("head-resources", false, "Проверять файлы (PDF, изображения и т.п.) HEAD-запросом вместо частичного GET")
+	crawlResources := flag.Bool("crawl-resources", false, "Проверять внутренние ссылки на файлы без разбора их содержимого")
+	checkImages := flag.Bool("check-images", false, "Запрашивать каждое изображение, og:image и twitter:image для проверки статуса и размера")
+	maxImageSize := flag.Int64("max-image-size", 100<<10, "Размер изображения в байтах, при превышении которого оно считается слишком большим")
+	requestsPerSecond := flag.Float64("requests-per-second", 0, "Максимальная частота запросов к одному хосту (0 — без ограничения, кроме задержки между запросами)")
+	maxRedirects := flag.Int("max-redirects", 10, "Максимальное количество переходов по редиректам")
 	flag.Parse()
 
 	if *url == "" {
@@ -31,9 +50,30 @@ func main() {
 	// Создаем конфигурацию краулера
 	config := &crawler.Config{
 		UserAgent:    *userAgent,
-		RequestDelay: time.Second,
+		RequestDelay: *requestDelay,
 		MaxRetries:   3,
 		Timeout:      *timeout,
+
+		IgnoreRobotsTxt: *ignoreRobots,
+		IgnoreSitemaps:  *ignoreSitemaps,
+		FrontierDir:     *frontierDir,
+		MaxRedirects:    *maxRedirects,
+
+		Workers:           *workers,
+		MaxConnsPerHost:   *maxConnsPerHost,
+		MaxQueueSize:      *maxQueueSize,
+		RequestsPerSecond: *requestsPerSecond,
+
+		MaxBodySize:    *maxBodySize,
+		CrawlResources: *crawlResources,
+		CheckImages:    *checkImages,
+		MaxImageSize:   *maxImageSize,
+	}
+	if *headResources {
+		config.ResourceFetch = crawler.ResourceFetchHead
+	}
+	if err := config.Validate(); err != nil {
+		log.Fatalf("Некорректная конфигурация: %v", err)
 	}
 
 	if *singlePage {
@@ -50,7 +90,10 @@ func main() {
 		fmt.Printf("Используется User-Agent: %s\n", *userAgent)
 		result, err := c.CrawlPage(ctx, *url)
 		if err != nil {
-			log.Fatalf("Ошибка при краулинге: %v", err)
+			if result == nil {
+				log.Fatalf("Ошибка при краулинге: %v", err)
+			}
+			log.Printf("Страница вернула ошибку: %v", err)
 		}
 
 		encoder := json.NewEncoder(os.Stdout)
@@ -75,7 +118,10 @@ func main() {
 
 		result, err := sc.CrawlSite(ctx)
 		if err != nil {
-			log.Fatalf("Ошибка при краулинге сайта: %v", err)
+			if result == nil {
+				log.Fatalf("Ошибка при краулинге сайта: %v", err)
+			}
+			log.Printf("Краулинг прерван (%v), выводим частичные результаты", err)
 		}
 
 		// Выводим статистику
@@ -84,6 +130,89 @@ func main() {
 		fmt.Printf("Всего слов: %d\n", result.Statistics.TotalWordCount)
 		fmt.Printf("Среднее количество слов на страницу: %d\n", result.Statistics.AverageWordCount)
 		fmt.Printf("Время выполнения: %v\n", result.EndTime.Sub(result.StartTime))
+		if result.DroppedURLs > 0 {
+			fmt.Printf("Ссылок, отброшенных из-за переполнения очереди: %d\n", result.DroppedURLs)
+		}
+
+		if len(result.Statistics.StatusCodes) > 0 {
+			fmt.Printf("\nHTTP-статусы:\n")
+			for code, count := range result.Statistics.StatusCodes {
+				fmt.Printf("  - %d: %d\n", code, count)
+			}
+		}
+
+		if len(result.Statistics.ContentTypes) > 0 {
+			fmt.Printf("\nТипы содержимого:\n")
+			for mediaType, count := range result.Statistics.ContentTypes {
+				fmt.Printf("  - %s: %d\n", mediaType, count)
+			}
+		}
+
+		if len(result.Statistics.BrokenLinks) > 0 {
+			fmt.Printf("\nБитые ссылки (%d):\n", len(result.Statistics.BrokenLinks))
+			for _, link := range result.Statistics.BrokenLinks {
+				fmt.Printf("  - %s (%d)\n", link.URL, link.StatusCode)
+				for _, source := range link.Sources {
+					fmt.Printf("      со страницы %s, анкор %q\n", source.PageURL, source.Anchor)
+				}
+			}
+		}
+
+		if len(result.Statistics.FlakyPages) > 0 {
+			fmt.Printf("\nСтраницы, загруженные после повторов (%d):\n", len(result.Statistics.FlakyPages))
+			for _, url := range result.Statistics.FlakyPages {
+				fmt.Printf("  - %s (попыток: %d)\n", url, result.Pages[url].Attempts)
+			}
+		}
+
+		if len(result.Statistics.CharsetMismatches) > 0 {
+			fmt.Printf("\nСтраницы с несовпадением кодировки (%d):\n", len(result.Statistics.CharsetMismatches))
+			for _, url := range result.Statistics.CharsetMismatches {
+				page := result.Pages[url]
+				fmt.Printf("  - %s (заголовок: %q, meta: %q, фактическая: %q)\n",
+					url, page.HeaderCharset, page.MetaCharset, page.DetectedCharset)
+			}
+		}
+
+		printRedirects := func(title string, redirects []crawler.Redirect) {
+			if len(redirects) == 0 {
+				return
+			}
+			fmt.Printf("\n%s (%d):\n", title, len(redirects))
+			for _, redirect := range redirects {
+				fmt.Printf("  - %s → %s (%d)\n", redirect.URL, redirect.FinalURL, redirect.FinalStatusCode)
+				for _, hop := range redirect.Hops {
+					fmt.Printf("      %s [%s] → %s\n", hop.URL, hop.Type, hop.Location)
+				}
+			}
+		}
+		printRedirects("Цепочки редиректов", result.Redirects.Chains)
+		printRedirects("Циклические редиректы", result.Redirects.Loops)
+		printRedirects("Редиректы на страницы 4xx", result.Redirects.ToClientErrors)
+		printRedirects("Редиректы скриптом", result.Redirects.JavaScript)
+		if len(result.Redirects.LinkedRedirects) > 0 {
+			fmt.Printf("\nВнутренние ссылки на редиректы (%d):\n", len(result.Redirects.LinkedRedirects))
+			for _, link := range result.Redirects.LinkedRedirects {
+				fmt.Printf("  - %s → %s\n", link.URL, link.FinalURL)
+				fmt.Printf("      со страницы %s, анкор %q\n", link.PageURL, link.Anchor)
+			}
+		}
+
+		if len(result.Statistics.DepthDistribution) > 0 {
+			fmt.Printf("\nРаспределение страниц по глубине клика:\n")
+			depths := make([]int, 0, len(result.Statistics.DepthDistribution))
+			for depth := range result.Statistics.DepthDistribution {
+				depths = append(depths, depth)
+			}
+			sort.Ints(depths)
+			for _, depth := range depths {
+				if depth < 0 {
+					fmt.Printf("  - недостижимы по ссылкам: %d\n", result.Statistics.DepthDistribution[depth])
+					continue
+				}
+				fmt.Printf("  - глубина %d: %d\n", depth, result.Statistics.DepthDistribution[depth])
+			}
+		}
 
 		if len(result.Statistics.MissingMetaDesc) > 0 {
 			fmt.Printf("\nСтраницы без мета-описания (%d):\n", len(result.Statistics.MissingMetaDesc))
@@ -99,6 +228,229 @@ func main() {
 			}
 		}
 
+		printURLs := func(title string, urls []string) {
+			if len(urls) == 0 {
+				return
+			}
+			fmt.Printf("\n%s (%d):\n", title, len(urls))
+			for _, url := range urls {
+				fmt.Printf("  - %s\n", url)
+			}
+		}
+		headings := result.Statistics.Headings
+		printURLs("Страницы без H1", headings.MissingH1)
+		printURLs("Страницы с несколькими H1", headings.MultipleH1)
+		printURLs("Страницы, где H1 совпадает с title", headings.H1MatchesTitle)
+		if len(headings.DuplicateH1) > 0 {
+			fmt.Printf("\nОдинаковые H1 на разных страницах (%d):\n", len(headings.DuplicateH1))
+			for _, duplicate := range headings.DuplicateH1 {
+				fmt.Printf("  - %q: %s\n", duplicate.Text, strings.Join(duplicate.Pages, ", "))
+			}
+		}
+		if len(headings.SkippedLevels) > 0 {
+			fmt.Printf("\nПропущенные уровни заголовков (%d):\n", len(headings.SkippedLevels))
+			for _, skip := range headings.SkippedLevels {
+				fmt.Printf("  - %s: H%d %q → H%d %q\n", skip.URL, skip.From.Level, skip.From.Text, skip.To.Level, skip.To.Text)
+			}
+		}
+
+		printLinkUsages := func(title string, usages []crawler.LinkUsage) {
+			if len(usages) == 0 {
+				return
+			}
+			fmt.Printf("\n%s (%d):\n", title, len(usages))
+			for _, usage := range usages {
+				if len(usage.Link.Rel) > 0 {
+					fmt.Printf("  - %s → %s (rel=%q)\n", usage.PageURL, usage.Link.URL, strings.Join(usage.Link.Rel, " "))
+				} else {
+					fmt.Printf("  - %s → %s\n", usage.PageURL, usage.Link.URL)
+				}
+			}
+		}
+		printLinkUsages("Внутренние ссылки с nofollow", result.Links.NoFollowInternal)
+		printLinkUsages("Ссылки без текста", result.Links.EmptyAnchors)
+		if len(result.Links.Anchors) > 0 {
+			fmt.Printf("\nТексты ссылок на страницы:\n")
+			for target, anchors := range result.Links.Anchors {
+				fmt.Printf("  - %s\n", target)
+				for anchor, count := range anchors {
+					fmt.Printf("      %q: %d\n", anchor, count)
+				}
+			}
+		}
+
+		graph := result.LinkGraph
+		fmt.Printf("\nГраф внутренних ссылок: страниц %d, связей %d\n", graph.Pages, graph.Edges)
+		printScores := func(title string, scores []crawler.PageScore) {
+			if len(scores) == 0 {
+				return
+			}
+			fmt.Printf("\n%s (%d):\n", title, len(scores))
+			for _, score := range scores {
+				fmt.Printf("  - %s: PageRank %.4f, входящих %d (без nofollow %d), исходящих %d\n",
+					score.URL, score.PageRank, score.Inlinks, score.FollowedInlinks, score.Outlinks)
+			}
+		}
+		printScores("Страницы с наибольшим PageRank", graph.TopPages)
+		printScores("Хабы", graph.Hubs)
+		printScores("Страницы без входящих ссылок", graph.Orphans)
+		printScores("Страницы не больше чем с одной ссылкой без nofollow", graph.WeakPages)
+
+		fmt.Printf("\nИзображения: %d\n", len(result.Images.Images))
+		printImageUsages := func(title string, usages []crawler.ImageUsage) {
+			if len(usages) == 0 {
+				return
+			}
+			fmt.Printf("\n%s (%d):\n", title, len(usages))
+			for _, usage := range usages {
+				fmt.Printf("  - %s на странице %s\n", usage.ImageURL, usage.PageURL)
+			}
+		}
+		printImageUsages("Изображения без alt", result.Images.MissingAlt)
+		printImageUsages("Изображения без width/height", result.Images.MissingDimensions)
+		if len(result.Images.Broken) > 0 {
+			fmt.Printf("\nБитые изображения (%d):\n", len(result.Images.Broken))
+			for _, image := range result.Images.Broken {
+				if image.Error != nil {
+					fmt.Printf("  - %s: %v\n", image.URL, image.Error)
+				} else {
+					fmt.Printf("  - %s (%d)\n", image.URL, image.StatusCode)
+				}
+				fmt.Printf("      на страницах: %s\n", strings.Join(image.Pages, ", "))
+			}
+		}
+		if len(result.Images.Oversized) > 0 {
+			fmt.Printf("\nСлишком большие изображения (%d):\n", len(result.Images.Oversized))
+			for _, image := range result.Images.Oversized {
+				fmt.Printf("  - %s (%d КБ)\n", image.URL, image.ContentLength>>10)
+			}
+		}
+
+		structured := result.StructuredData
+		fmt.Printf("\nСтруктурированные данные: %d из %d страниц\n", structured.PagesWithData, structured.ContentPages)
+		for itemType, count := range structured.Types {
+			fmt.Printf("  - %s: %d\n", itemType, count)
+		}
+		if len(structured.Errors) > 0 {
+			fmt.Printf("\nОшибки структурированных данных (%d):\n", len(structured.Errors))
+			for _, issue := range structured.Errors {
+				fmt.Printf("  - %s: %s [%s] без обязательного свойства %s\n", issue.PageURL, issue.Type, issue.Format, issue.Property)
+			}
+		}
+		if len(structured.Warnings) > 0 {
+			missing := make(map[string]int)
+			for _, issue := range structured.Warnings {
+				missing[fmt.Sprintf("%s (%s)", issue.Property, issue.Type)]++
+			}
+			fmt.Printf("\nОтсутствующие рекомендуемые свойства:\n")
+			for property, count := range missing {
+				fmt.Printf("  - %s: %d\n", property, count)
+			}
+		}
+		for pageURL, errs := range structured.ParseErrors {
+			fmt.Printf("\nОшибки разбора JSON-LD на %s:\n", pageURL)
+			for _, err := range errs {
+				fmt.Printf("  - %s\n", err)
+			}
+		}
+
+		social := result.Social
+		printURLs("Страницы без og:title", social.MissingTitle)
+		printURLs("Страницы без og:image", social.MissingImage)
+		printURLs("Страницы без og:url", social.MissingURL)
+		if len(social.URLMismatches) > 0 {
+			fmt.Printf("\nog:url не совпадает с canonical (%d):\n", len(social.URLMismatches))
+			for _, mismatch := range social.URLMismatches {
+				fmt.Printf("  - %s: og:url %s, canonical %s\n", mismatch.PageURL, mismatch.OGURL, mismatch.Canonical)
+			}
+		}
+		if len(social.UnreachableImages) > 0 {
+			fmt.Printf("\nНедоступные изображения для соцсетей (%d):\n", len(social.UnreachableImages))
+			for _, image := range social.UnreachableImages {
+				if image.Error != nil {
+					fmt.Printf("  - %s: %v\n", image.URL, image.Error)
+				} else {
+					fmt.Printf("  - %s (%d)\n", image.URL, image.StatusCode)
+				}
+			}
+		}
+		if len(social.SmallImages) > 0 {
+			fmt.Printf("\nСлишком маленькие изображения для соцсетей (%d):\n", len(social.SmallImages))
+			for _, image := range social.SmallImages {
+				fmt.Printf("  - %s (%d×%d)\n", image.URL, image.Width, image.Height)
+			}
+		}
+		if len(social.TwitterCards) > 0 {
+			fmt.Printf("\nПроблемы Twitter Card (%d):\n", len(social.TwitterCards))
+			for _, issue := range social.TwitterCards {
+				fmt.Printf("  - %s: %s (twitter:card %q)\n", issue.PageURL, issue.Problem, issue.Card)
+			}
+		}
+
+		hreflang := result.Hreflang
+		if hreflang.Pages > 0 {
+			fmt.Printf("\nСтраницы с hreflang: %d\n", hreflang.Pages)
+		}
+		printURLs("Страницы с hreflang без x-default", hreflang.MissingXDefault)
+		printHreflang := func(title string, issues []crawler.HreflangIssue) {
+			if len(issues) == 0 {
+				return
+			}
+			fmt.Printf("\n%s (%d):\n", title, len(issues))
+			for _, issue := range issues {
+				switch {
+				case issue.Canonical != "":
+					fmt.Printf("  - %s: %s → %s (canonical %s)\n", issue.PageURL, issue.Lang, issue.URL, issue.Canonical)
+				case issue.StatusCode != 0:
+					fmt.Printf("  - %s: %s → %s (%d)\n", issue.PageURL, issue.Lang, issue.URL, issue.StatusCode)
+				default:
+					fmt.Printf("  - %s: %s → %s\n", issue.PageURL, issue.Lang, issue.URL)
+				}
+			}
+		}
+		printHreflang("Недопустимые коды hreflang", hreflang.InvalidCodes)
+		printHreflang("hreflang без ответной ссылки", hreflang.MissingReturnLinks)
+		printHreflang("hreflang на страницы не с ответом 200", hreflang.ToNon200)
+		printHreflang("hreflang на неканонические страницы", hreflang.ToNonCanonical)
+		if len(hreflang.Conflicts) > 0 {
+			fmt.Printf("\nКонфликтующие hreflang (%d):\n", len(hreflang.Conflicts))
+			for _, conflict := range hreflang.Conflicts {
+				fmt.Printf("  - %s: %s → %s\n", conflict.PageURL, conflict.Lang, strings.Join(conflict.URLs, ", "))
+			}
+		}
+
+		indexability := result.Statistics.Indexability
+		fmt.Printf("\nИндексируемые страницы: %d\n", indexability.Indexable)
+		printURLs("Страницы с noindex", indexability.NoIndex)
+		printURLs("Страницы с nofollow", indexability.NoFollow)
+		printURLs("Страницы без canonical", indexability.MissingCanonical)
+		printCanonicals := func(title string, issues []crawler.CanonicalIssue) {
+			if len(issues) == 0 {
+				return
+			}
+			fmt.Printf("\n%s (%d):\n", title, len(issues))
+			for _, issue := range issues {
+				switch {
+				case len(issue.Chain) > 0:
+					fmt.Printf("  - %s → %s\n", issue.URL, strings.Join(issue.Chain, " → "))
+				case issue.StatusCode != 0:
+					fmt.Printf("  - %s → %s (%d)\n", issue.URL, issue.Canonical, issue.StatusCode)
+				default:
+					fmt.Printf("  - %s → %s\n", issue.URL, issue.Canonical)
+				}
+			}
+		}
+		printCanonicals("Канонизированные страницы", indexability.Canonicalised)
+		printCanonicals("Canonical на страницы не с ответом 200", indexability.CanonicalToNon200)
+		printCanonicals("Цепочки canonical", indexability.CanonicalChains)
+
+		if len(result.Statistics.LinkTypes) > 0 {
+			fmt.Printf("\nСсылки по типам:\n")
+			for linkType, count := range result.Statistics.LinkTypes {
+				fmt.Printf("  - %s: %d\n", linkType, count)
+			}
+		}
+
 		if len(result.Statistics.UniqueDomains) > 0 {
 			fmt.Printf("\nВнешние домены:\n")
 			for domain, count := range result.Statistics.UniqueDomains {
@@ -106,6 +458,43 @@ func main() {
 			}
 		}
 
+		if len(result.Sitemap.Sitemaps) > 0 {
+			fmt.Printf("\nSitemap: файлов %d, URL %d\n", len(result.Sitemap.Sitemaps), result.Sitemap.TotalURLs)
+			if len(result.Sitemap.NotLinked) > 0 {
+				fmt.Printf("URL из sitemap без внутренних ссылок (%d):\n", len(result.Sitemap.NotLinked))
+				for _, url := range result.Sitemap.NotLinked {
+					fmt.Printf("  - %s\n", url)
+				}
+			}
+			if len(result.Sitemap.MissingFromSitemap) > 0 {
+				fmt.Printf("Страницы, отсутствующие в sitemap (%d):\n", len(result.Sitemap.MissingFromSitemap))
+				for _, url := range result.Sitemap.MissingFromSitemap {
+					fmt.Printf("  - %s\n", url)
+				}
+			}
+		}
+
+		if len(result.Normalization.Variants) > 0 {
+			fmt.Printf("\nДубли URL, сведенные нормализацией (%d):\n", len(result.Normalization.Variants))
+			for normalized, variants := range result.Normalization.Variants {
+				fmt.Printf("  - %s\n", normalized)
+				for _, variant := range variants {
+					fmt.Printf("      %s\n", variant)
+				}
+			}
+			fmt.Printf("Правила нормализации:\n")
+			for _, rule := range result.Normalization.Rules {
+				fmt.Printf("  - %s\n", rule)
+			}
+		}
+
+		if len(result.BlockedByRobots) > 0 {
+			fmt.Printf("\nЗапрещено robots.txt (%d):\n", len(result.BlockedByRobots))
+			for url, rule := range result.BlockedByRobots {
+				fmt.Printf("  - %s (%s)\n", url, rule)
+			}
+		}
+
 		if len(result.Errors) > 0 {
 			fmt.Printf("\nОшибки (%d):\n", len(result.Errors))
 			for url, err := range result.Errors {
@@ -0,0 +1,79 @@
+package crawler
+
+import "sync"
+
+// resultCollector потокобезопасно собирает результаты воркеров SiteCrawler.
+// Лимит страниц соблюдается через резервирование: воркер получает место
+// до загрузки страницы и освобождает его, если страница не сохранена.
+type resultCollector struct {
+	mu       sync.Mutex
+	result   *SiteCrawlerResult
+	maxPages int
+	reserved int
+}
+
+// newResultCollector создает сборщик результатов с лимитом страниц
+func newResultCollector(result *SiteCrawlerResult, maxPages int) *resultCollector {
+	return &resultCollector{
+		result:   result,
+		maxPages: maxPages,
+	}
+}
+
+// reserve резервирует место под страницу. Возвращает false, если лимит исчерпан.
+func (c *resultCollector) reserve() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.reserved >= c.maxPages {
+		return false
+	}
+	c.reserved++
+	return true
+}
+
+// release освобождает зарезервированное место
+func (c *resultCollector) release() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.reserved--
+}
+
+// addPage сохраняет страницу в зарезервированное место
+func (c *resultCollector) addPage(pageURL string, page *CrawlerResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.result.Pages[pageURL] = page
+	c.result.TotalPages = len(c.result.Pages)
+}
+
+// addError сохраняет ошибку краулинга URL
+func (c *resultCollector) addError(pageURL string, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.result.Errors[pageURL] = err
+}
+
+// addBlocked сохраняет URL, запрещенный robots.txt
+func (c *resultCollector) addBlocked(pageURL, rule string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.result.BlockedByRobots[pageURL] = rule
+}
+
+// addVariant сохраняет вариант написания нормализованного URL
+func (c *resultCollector) addVariant(normalized, variant string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, v := range c.result.Normalization.Variants[normalized] {
+		if v == variant {
+			return
+		}
+	}
+	c.result.Normalization.Variants[normalized] = append(c.result.Normalization.Variants[normalized], variant)
+}
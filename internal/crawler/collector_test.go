@@ -0,0 +1,129 @@
+package crawler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newSyntheticSite создает тестовый сайт из pages страниц, каждая из которых
+// ссылается на главную и на несколько других страниц
+func newSyntheticSite(t *testing.T, pages int) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(syntheticSiteHandler(pages))
+}
+
+// syntheticSiteHandler отдает страницы синтетического сайта из pages страниц
+func syntheticSiteHandler(pages int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := 0
+		if r.URL.Path != "/" {
+			if !strings.HasPrefix(r.URL.Path, "/page/") {
+				http.NotFound(w, r)
+				return
+			}
+			var err error
+			id, err = strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/page/"))
+			if err != nil || id < 0 || id >= pages {
+				http.NotFound(w, r)
+				return
+			}
+		}
+
+		var body strings.Builder
+		fmt.Fprintf(&body, "<html><head><title>Page %d</title></head><body><h1>Page %d</h1>", id, id)
+		body.WriteString(`<a href="/">Home</a>`)
+		for k := 1; k <= 5; k++ {
+			fmt.Fprintf(&body, `<a href="/page/%d">Link %d</a>`, (id*5+k)%pages, k)
+		}
+		body.WriteString("</body></html>")
+		w.Write([]byte(body.String()))
+	})
+}
+
+func TestResultCollector_Reserve(t *testing.T) {
+	result := &SiteCrawlerResult{
+		Pages:           make(map[string]*CrawlerResult),
+		Errors:          make(map[string]error),
+		BlockedByRobots: make(map[string]string),
+	}
+	collector := newResultCollector(result, 50)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 200; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			pageURL := fmt.Sprintf("https://example.com/%d", i)
+			if !collector.reserve() {
+				return
+			}
+			// Каждая третья страница завершается ошибкой и освобождает место
+			if i%3 == 0 {
+				collector.release()
+				collector.addError(pageURL, errors.New("fetch failed"))
+				return
+			}
+			collector.addPage(pageURL, &CrawlerResult{URL: pageURL})
+		}(i)
+	}
+	wg.Wait()
+
+	if len(result.Pages) > 50 {
+		t.Errorf("Expected at most 50 pages, got %d", len(result.Pages))
+	}
+	if result.TotalPages != len(result.Pages) {
+		t.Errorf("TotalPages = %d, len(Pages) = %d", result.TotalPages, len(result.Pages))
+	}
+}
+
+func TestSiteCrawler_ConcurrentLargeSite(t *testing.T) {
+	server := newSyntheticSite(t, 1000)
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.RequestDelay = 0
+	config.IgnoreSitemaps = true
+
+	tests := []struct {
+		name     string
+		maxPages int
+	}{
+		{"small limit", 20},
+		{"large limit", 300},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			crawler, err := NewSiteCrawler(server.URL, config, tt.maxPages, -1)
+			if err != nil {
+				t.Fatalf("Failed to create crawler: %v", err)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+
+			result, err := crawler.CrawlSite(ctx)
+			if err != nil {
+				t.Fatalf("CrawlSite failed: %v", err)
+			}
+			if result.TotalPages != tt.maxPages {
+				t.Errorf("Expected exactly %d pages, got %d", tt.maxPages, result.TotalPages)
+			}
+			if len(result.Pages) != tt.maxPages {
+				t.Errorf("Expected %d entries in Pages, got %d", tt.maxPages, len(result.Pages))
+			}
+			if len(result.Errors) != 0 {
+				t.Errorf("Expected no errors, got %d", len(result.Errors))
+			}
+		})
+	}
+}
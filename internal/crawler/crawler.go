@@ -2,6 +2,8 @@ package crawler
 
 import (
 	"context"
+	"fmt"
+	"net/http"
 	"time"
 )
 
@@ -11,13 +13,108 @@ type CrawlerResult struct {
 	Title           string
 	MetaDescription string
 	WordCount       int
-	Links           []string
-	Error           error
+	// Links содержит ссылки <a href> в порядке появления, разрешенные относительно страницы
+	Links []Link
+	// BaseHref содержит значение <base href> страницы
+	BaseHref string
+	Error    error
+
+	// Headings содержит заголовки h1–h6 в порядке появления на странице
+	Headings []Heading
+	// Images содержит изображения страницы в порядке появления
+	Images []Image
+	// StructuredData содержит элементы JSON-LD, Microdata и RDFa верхнего уровня в порядке появления
+	StructuredData []StructuredItem
+	// StructuredDataErrors содержит ошибки разбора блоков JSON-LD
+	StructuredDataErrors []string
+	// OpenGraph содержит свойства og:* (например, og:title) с первым значением каждого свойства
+	OpenGraph map[string]string
+	// TwitterCard содержит свойства twitter:* (например, twitter:card) с первым значением каждого свойства
+	TwitterCard map[string]string
+	// Hreflang содержит языковые версии страницы из HTML, заголовка Link и sitemap.
+	// Аннотации из sitemap добавляет SiteCrawler после краулинга.
+	Hreflang []HreflangLink
+	// Canonical — абсолютный URL из <link rel="canonical">
+	Canonical string
+	// MetaRobots содержит значения meta name="robots" и name="googlebot"
+	MetaRobots []string
+	// XRobotsTag содержит значения заголовков X-Robots-Tag
+	XRobotsTag []string
+	// Robots — директивы индексации из meta robots и X-Robots-Tag
+	Robots RobotsDirectives
+	// Indexable — страница доступна для индексации: ответ 200, нет noindex и canonical на другой URL.
+	// Заполняется SiteCrawler после краулинга.
+	Indexable bool
+
+	// StatusCode — HTTP-статус ответа на запрошенный URL
+	StatusCode int
+	// FinalURL — URL, с которого фактически получен ответ (после редиректов)
+	FinalURL string
+	// FinalStatusCode — HTTP-статус ответа конечного URL
+	FinalStatusCode int
+	// RedirectChain содержит переходы от запрошенного URL до FinalURL
+	RedirectChain []RedirectHop
+	// JavaScriptRedirect — адрес, на который конечная страница безусловно переходит скриптом.
+	// По такому редиректу краулер не переходит: содержимое страницы разбирается как есть.
+	JavaScriptRedirect string
+	// Attempts — количество попыток запроса конечного URL (больше 1, если были повторы)
+	Attempts int
+	// Headers содержит заголовки ответа конечного URL
+	Headers http.Header
+	// ContentType — значение заголовка Content-Type
+	ContentType string
+	// MIMEType — тип содержимого из Content-Type или определенный по первым байтам тела
+	MIMEType string
+	// ContentLength — значение заголовка Content-Length (-1, если не указан)
+	ContentLength int64
+	// ContentEncoding — схема сжатия ответа (gzip, deflate или пусто)
+	ContentEncoding string
+	// CompressedSize — размер тела, переданного по сети
+	CompressedSize int64
+	// UncompressedSize — размер тела после распаковки.
+	// Для ресурсов, не являющихся HTML, тело не загружается и размеры не заполняются.
+	UncompressedSize int64
+	// BodyTruncated — тело длиннее Config.MaxBodySize и было обрезано
+	BodyTruncated bool
+	// HeaderCharset — кодировка, объявленная в заголовке Content-Type
+	HeaderCharset string
+	// MetaCharset — кодировка, объявленная в <meta charset> или <meta http-equiv>
+	MetaCharset string
+	// DetectedCharset — кодировка, определенная по BOM или содержимому (пусто для текста только из ASCII)
+	DetectedCharset string
+	// Charset — кодировка, из которой документ перекодирован в UTF-8 перед разбором
+	Charset string
+	// CharsetMismatch — объявленные кодировки противоречат друг другу или содержимому
+	CharsetMismatch bool
+	// ServerIP — IP-адрес сервера, с которым установлено соединение
+	ServerIP string
+	// Protocol — версия протокола ответа (HTTP/1.1, HTTP/2.0)
+	Protocol string
+	// TTFB — время до получения первого байта ответа
+	TTFB time.Duration
+	// DownloadTime — полное время загрузки страницы
+	DownloadTime time.Duration
+
+	// Depth — глубина клика от начальной страницы сайта (-1, если страница не достижима по ссылкам)
+	Depth int
+	// ParentURL — страница, на которой впервые найдена ссылка на эту страницу
+	ParentURL string
+	// Anchor — текст ссылки, по которой страница была найдена
+	Anchor string
+	// Inlinks — количество HTML-страниц сайта, ссылающихся на эту страницу
+	Inlinks int
+	// FollowedInlinks — количество страниц, ссылающихся на эту страницу хотя бы одной ссылкой без nofollow
+	FollowedInlinks int
+	// Outlinks — количество уникальных HTML-страниц сайта, на которые ссылается страница
+	Outlinks int
+	// PageRank — внутренний PageRank страницы. Сумма по всем страницам не превышает 1.
+	PageRank float64
 }
 
 // Crawler определяет интерфейс для краулера
 type Crawler interface {
-	// CrawlPage краулит одну страницу и возвращает результат
+	// CrawlPage краулит одну страницу и возвращает результат.
+	// При ответе со статусом, отличным от 200, результат возвращается вместе с *StatusError.
 	CrawlPage(ctx context.Context, targetURL string) (*CrawlerResult, error)
 
 	// IsValidURL проверяет, является ли URL валидным для краулинга
@@ -28,8 +125,116 @@ type Crawler interface {
 type Config struct {
 	UserAgent    string
 	RequestDelay time.Duration
-	MaxRetries   int
-	Timeout      time.Duration
+	// MaxRetries — общее количество попыток запроса для политики повторов по умолчанию
+	MaxRetries int
+	Timeout    time.Duration
+
+	// Workers — количество параллельных воркеров SiteCrawler.
+	// Если не задано, используется значение по умолчанию (5).
+	Workers int
+
+	// MaxQueueSize — максимальное количество URL в очереди SiteCrawler.
+	// Ссылки сверх лимита отбрасываются. Если не задано, размер очереди не ограничен.
+	MaxQueueSize int
+
+	// MaxBodySize — максимальный размер тела ответа в байтах. Более длинные ответы обрезаются.
+	// Если не задан, используется значение по умолчанию (10 МБ).
+	MaxBodySize int64
+
+	// ResourceFetch определяет загрузку ресурсов, не являющихся HTML.
+	// Если не задан, используется ResourceFetchPartial.
+	ResourceFetch ResourceFetchMode
+
+	// CrawlResources включает проверку внутренних ссылок на файлы (PDF, изображения и т.п.).
+	// Такие ресурсы сохраняются в результатах со статусом, типом и размером без разбора.
+	CrawlResources bool
+
+	// CheckImages включает запрос каждого уникального изображения страниц, og:image и twitter:image
+	// для проверки статуса и размера
+	CheckImages bool
+
+	// MaxImageSize — размер изображения в байтах, при превышении которого оно считается слишком большим.
+	// Если не задан, используется значение по умолчанию (100 КБ).
+	MaxImageSize int64
+
+	// Transport — HTTP-транспорт для всех запросов краулера.
+	// Если не задан, создается транспорт NewTransport, общий для всего краулинга сайта.
+	Transport http.RoundTripper
+
+	// DialTimeout, TLSHandshakeTimeout и ResponseHeaderTimeout — таймауты этапов запроса
+	// для транспорта по умолчанию. Если не заданы, используются 10s, 10s и 20s.
+	DialTimeout           time.Duration
+	TLSHandshakeTimeout   time.Duration
+	ResponseHeaderTimeout time.Duration
+
+	// RequestsPerSecond — максимальная частота запросов к одному хосту.
+	// Интервал между запросами — наибольший из RequestDelay, 1/RequestsPerSecond и Crawl-delay из robots.txt.
+	RequestsPerSecond float64
+
+	// MaxConnsPerHost — максимальное количество параллельных запросов к одному хосту.
+	// Если не задано, используется значение по умолчанию (2). Значение больше Workers
+	// ограничивается количеством воркеров.
+	MaxConnsPerHost int
+
+	// DisableAdaptiveThrottling отключает автоматическое замедление запросов
+	// при росте времени ответа или доли ошибок 5xx
+	DisableAdaptiveThrottling bool
+
+	// RetryPolicy определяет повторы запросов.
+	// Если не задана, используется ExponentialBackoff с MaxRetries попытками.
+	RetryPolicy RetryPolicy
+
+	// RetryBudget — максимальное количество повторов запросов к одному хосту.
+	// Если не задано, используется значение по умолчанию (100), отрицательное значение снимает ограничение.
+	RetryBudget int
+
+	// IgnoreRobotsTxt отключает проверку правил robots.txt
+	IgnoreRobotsTxt bool
+
+	// IgnoreSitemaps отключает загрузку sitemap и добавление их URL в очередь
+	IgnoreSitemaps bool
+
+	// FrontierDir — каталог для очереди URL на диске.
+	// Если не задан, очередь хранится в памяти.
+	FrontierDir string
+
+	// MaxRedirects — максимальное количество переходов по редиректам.
+	// Если не задано, используется значение по умолчанию (10).
+	MaxRedirects int
+
+	// Normalization настраивает нормализацию URL при дедупликации
+	Normalization NormalizationConfig
+}
+
+// Validate проверяет значения конфигурации
+func (c *Config) Validate() error {
+	switch {
+	case c.Workers < 0:
+		return fmt.Errorf("%w: workers must not be negative, got %d", ErrInvalidConfig, c.Workers)
+	case c.MaxConnsPerHost < 0:
+		return fmt.Errorf("%w: max connections per host must not be negative, got %d", ErrInvalidConfig, c.MaxConnsPerHost)
+	case c.MaxQueueSize < 0:
+		return fmt.Errorf("%w: max queue size must not be negative, got %d", ErrInvalidConfig, c.MaxQueueSize)
+	case c.RequestsPerSecond < 0:
+		return fmt.Errorf("%w: requests per second must not be negative, got %g", ErrInvalidConfig, c.RequestsPerSecond)
+	case c.RequestDelay < 0:
+		return fmt.Errorf("%w: request delay must not be negative, got %v", ErrInvalidConfig, c.RequestDelay)
+	case c.Timeout < 0:
+		return fmt.Errorf("%w: timeout must not be negative, got %v", ErrInvalidConfig, c.Timeout)
+	case c.DialTimeout < 0 || c.TLSHandshakeTimeout < 0 || c.ResponseHeaderTimeout < 0:
+		return fmt.Errorf("%w: transport timeouts must not be negative", ErrInvalidConfig)
+	case c.MaxRetries < 0:
+		return fmt.Errorf("%w: max retries must not be negative, got %d", ErrInvalidConfig, c.MaxRetries)
+	case c.MaxBodySize < 0:
+		return fmt.Errorf("%w: max body size must not be negative, got %d", ErrInvalidConfig, c.MaxBodySize)
+	case c.MaxImageSize < 0:
+		return fmt.Errorf("%w: max image size must not be negative, got %d", ErrInvalidConfig, c.MaxImageSize)
+	case c.ResourceFetch != "" && c.ResourceFetch != ResourceFetchPartial && c.ResourceFetch != ResourceFetchHead:
+		return fmt.Errorf("%w: unknown resource fetch mode %q", ErrInvalidConfig, c.ResourceFetch)
+	case c.MaxRedirects < 0:
+		return fmt.Errorf("%w: max redirects must not be negative, got %d", ErrInvalidConfig, c.MaxRedirects)
+	}
+	return nil
 }
 
 // DefaultConfig возвращает конфигурацию по умолчанию
@@ -39,5 +244,6 @@ func DefaultConfig() *Config {
 		RequestDelay: time.Second,
 		MaxRetries:   3,
 		Timeout:      30 * time.Second,
+		MaxRedirects: defaultMaxRedirects,
 	}
 }
@@ -0,0 +1,42 @@
+package crawler
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*Config)
+		valid  bool
+	}{
+		{"default", func(c *Config) {}, true},
+		{"zero value", func(c *Config) { *c = Config{} }, true},
+		{"large site", func(c *Config) { c.Workers = 64; c.MaxConnsPerHost = 8; c.MaxQueueSize = 500000 }, true},
+		{"negative workers", func(c *Config) { c.Workers = -1 }, false},
+		{"negative conns per host", func(c *Config) { c.MaxConnsPerHost = -1 }, false},
+		{"conns per host above workers", func(c *Config) { c.Workers = 2; c.MaxConnsPerHost = 4 }, true},
+		{"negative queue size", func(c *Config) { c.MaxQueueSize = -10 }, false},
+		{"negative rate", func(c *Config) { c.RequestsPerSecond = -1 }, false},
+		{"negative delay", func(c *Config) { c.RequestDelay = -time.Second }, false},
+		{"negative timeout", func(c *Config) { c.Timeout = -time.Second }, false},
+		{"negative retries", func(c *Config) { c.MaxRetries = -1 }, false},
+		{"negative redirects", func(c *Config) { c.MaxRedirects = -1 }, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			tt.modify(config)
+			err := config.Validate()
+			if tt.valid && err != nil {
+				t.Errorf("Expected valid config, got %v", err)
+			}
+			if !tt.valid && !errors.Is(err, ErrInvalidConfig) {
+				t.Errorf("Expected ErrInvalidConfig, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,246 @@
+package crawler
+
+import (
+	"bytes"
+	"mime"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/charset"
+	"golang.org/x/text/encoding"
+)
+
+// metaPrescanLen — количество байт начала документа, в которых ищется <meta charset>
+const metaPrescanLen = 4096
+
+// defaultCharset используется, если кодировка не объявлена и не определена по содержимому
+const defaultCharset = "windows-1252"
+
+// cyrillicFrequent — самые частые строчные буквы русского текста
+const cyrillicFrequent = "оеаинтсрвлкмдпу"
+
+// cyrillicCharsets — однобайтовые кодировки, определяемые по содержимому
+var cyrillicCharsets = []string{"windows-1251", "koi8-r"}
+
+// byteOrderMarks — метки порядка байт и соответствующие им кодировки
+var byteOrderMarks = []struct {
+	bom     []byte
+	charset string
+}{
+	{[]byte{0xEF, 0xBB, 0xBF}, "utf-8"},
+	{[]byte{0xFE, 0xFF}, "utf-16be"},
+	{[]byte{0xFF, 0xFE}, "utf-16le"},
+}
+
+// decodeBody определяет кодировку HTML-документа и перекодирует его в UTF-8.
+// Приоритет источников как в браузерах: BOM, заголовок Content-Type, <meta charset>,
+// затем определение по содержимому. Найденные кодировки сохраняются в результате.
+func decodeBody(result *CrawlerResult, body []byte) []byte {
+	result.HeaderCharset = headerCharset(result.ContentType)
+	result.MetaCharset = metaCharset(body)
+
+	bomName, bomLen := bomCharset(body)
+	body = body[bomLen:]
+
+	if bomName != "" {
+		result.DetectedCharset = bomName
+	} else if result.BodyTruncated {
+		// Обрезанное тело может заканчиваться на середине многобайтового символа
+		result.DetectedCharset = sniffCharset(trimIncompleteRune(body))
+	} else {
+		result.DetectedCharset = sniffCharset(body)
+	}
+
+	result.Charset = defaultCharset
+	for _, name := range []string{bomName, result.HeaderCharset, result.MetaCharset, result.DetectedCharset} {
+		if _, ok := lookupCharset(name); ok {
+			result.Charset = name
+			break
+		}
+	}
+	result.CharsetMismatch = charsetMismatch(result)
+
+	if result.Charset == "utf-8" {
+		return body
+	}
+	enc, _ := lookupCharset(result.Charset)
+	decoded, err := enc.NewDecoder().Bytes(body)
+	if err != nil {
+		// Недопустимые последовательности заменяются декодером, ошибка означает сбой кодировки
+		return body
+	}
+	return decoded
+}
+
+// charsetMismatch проверяет, что объявленные кодировки согласованы между собой
+// и с кодировкой, определенной по содержимому
+func charsetMismatch(result *CrawlerResult) bool {
+	declared := []string{result.HeaderCharset, result.MetaCharset}
+	if declared[0] != "" && declared[1] != "" && declared[0] != declared[1] {
+		return true
+	}
+	if result.DetectedCharset == "" {
+		return false
+	}
+	for _, name := range declared {
+		if name == "" || name == result.DetectedCharset {
+			continue
+		}
+		// Однобайтовые кодировки по содержимому различаются только среди русских,
+		// поэтому другие объявленные однобайтовые кодировки не сравниваются с определенной
+		if !isUnicodeCharset(name) && !isUnicodeCharset(result.DetectedCharset) && !slices.Contains(cyrillicCharsets, name) {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// isUnicodeCharset проверяет, что кодировка относится к Unicode
+func isUnicodeCharset(name string) bool {
+	return strings.HasPrefix(name, "utf-")
+}
+
+// headerCharset возвращает кодировку из заголовка Content-Type
+func headerCharset(contentType string) string {
+	return canonicalCharset(headerCharsetLabel(contentType))
+}
+
+// bomCharset определяет кодировку по метке порядка байт.
+// Возвращает кодировку и длину метки.
+func bomCharset(body []byte) (string, int) {
+	for _, mark := range byteOrderMarks {
+		if bytes.HasPrefix(body, mark.bom) {
+			return mark.charset, len(mark.bom)
+		}
+	}
+	return "", 0
+}
+
+// metaCharset ищет кодировку в <meta charset> и <meta http-equiv="Content-Type">
+// в начале документа
+func metaCharset(body []byte) string {
+	if len(body) > metaPrescanLen {
+		body = body[:metaPrescanLen]
+	}
+
+	z := html.NewTokenizer(bytes.NewReader(body))
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return ""
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := z.TagName()
+			switch string(name) {
+			case "body":
+				return ""
+			case "meta":
+				var httpEquiv, content, label string
+				for hasAttr {
+					var key, val []byte
+					key, val, hasAttr = z.TagAttr()
+					switch string(key) {
+					case "charset":
+						label = string(val)
+					case "http-equiv":
+						httpEquiv = string(val)
+					case "content":
+						content = string(val)
+					}
+				}
+				if label == "" && strings.EqualFold(httpEquiv, "content-type") {
+					label = headerCharsetLabel(content)
+				}
+				if name := canonicalCharset(label); name != "" {
+					return name
+				}
+			}
+		}
+	}
+}
+
+// headerCharsetLabel извлекает значение charset из строки вида "text/html; charset=..."
+func headerCharsetLabel(contentType string) string {
+	_, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+	return params["charset"]
+}
+
+// trimIncompleteRune отбрасывает незавершенную UTF-8 последовательность в конце body
+func trimIncompleteRune(body []byte) []byte {
+	for i := 1; i < utf8.UTFMax && i <= len(body); i++ {
+		start := len(body) - i
+		if !utf8.RuneStart(body[start]) {
+			continue
+		}
+		if !utf8.FullRune(body[start:]) {
+			return body[:start]
+		}
+		break
+	}
+	return body
+}
+
+// sniffCharset определяет кодировку по содержимому.
+// Для текста только из ASCII возвращает пустую строку: он совместим с любой кодировкой.
+// Однобайтовый текст проверяется на русские кодировки windows-1251 и KOI8-R.
+func sniffCharset(body []byte) string {
+	ascii := true
+	for _, b := range body {
+		if b >= utf8.RuneSelf {
+			ascii = false
+			break
+		}
+	}
+	switch {
+	case ascii:
+		return ""
+	case utf8.Valid(body):
+		return "utf-8"
+	}
+
+	best, bestScore := "", 0
+	for _, name := range cyrillicCharsets {
+		enc, _ := lookupCharset(name)
+		decoded, err := enc.NewDecoder().Bytes(body)
+		if err != nil {
+			continue
+		}
+		score := 0
+		for _, r := range string(decoded) {
+			if strings.ContainsRune(cyrillicFrequent, r) {
+				score++
+			}
+		}
+		if score > bestScore {
+			best, bestScore = name, score
+		}
+	}
+	return best
+}
+
+// canonicalCharset приводит метку кодировки к каноническому имени ("cp1251" → "windows-1251").
+// Неизвестные метки возвращаются в нижнем регистре.
+func canonicalCharset(label string) string {
+	label = strings.ToLower(strings.Trim(strings.TrimSpace(label), `"'`))
+	if label == "" {
+		return ""
+	}
+	if _, name := charset.Lookup(label); name != "" {
+		return name
+	}
+	return label
+}
+
+// lookupCharset возвращает кодировку по имени или метке
+func lookupCharset(name string) (encoding.Encoding, bool) {
+	if name == "" {
+		return nil, false
+	}
+	enc, _ := charset.Lookup(name)
+	return enc, enc != nil
+}
@@ -0,0 +1,128 @@
+package crawler
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+func TestDecodeBody(t *testing.T) {
+	encode := func(enc *charmap.Charmap, s string) string {
+		b, err := enc.NewEncoder().String(s)
+		if err != nil {
+			t.Fatalf("Failed to encode %q: %v", s, err)
+		}
+		return b
+	}
+	text := "Привет, мир! Это страница на русском языке."
+
+	tests := []struct {
+		name             string
+		contentType      string
+		body             string
+		expectedCharset  string
+		expectedMeta     string
+		expectedMismatch bool
+	}{
+		{
+			name:            "utf-8 without declaration",
+			contentType:     "text/html",
+			body:            "<html><body>" + text + "</body></html>",
+			expectedCharset: "utf-8",
+		},
+		{
+			name:            "windows-1251 from header",
+			contentType:     "text/html; charset=windows-1251",
+			body:            "<html><body>" + encode(charmap.Windows1251, text) + "</body></html>",
+			expectedCharset: "windows-1251",
+		},
+		{
+			name:            "koi8-r from meta charset",
+			contentType:     "text/html",
+			body:            `<html><head><meta charset="KOI8-R"></head><body>` + encode(charmap.KOI8R, text) + "</body></html>",
+			expectedCharset: "koi8-r",
+			expectedMeta:    "koi8-r",
+		},
+		{
+			name:            "cp1251 from http-equiv",
+			contentType:     "text/html",
+			body:            `<html><head><meta http-equiv="Content-Type" content="text/html; charset=cp1251"></head><body>` + encode(charmap.Windows1251, text) + "</body></html>",
+			expectedCharset: "windows-1251",
+			expectedMeta:    "windows-1251",
+		},
+		{
+			name:            "undeclared koi8-r detected by content",
+			contentType:     "text/html",
+			body:            "<html><body>" + encode(charmap.KOI8R, text) + "</body></html>",
+			expectedCharset: "koi8-r",
+		},
+		{
+			name:             "bom overrides header",
+			contentType:      "text/html; charset=windows-1251",
+			body:             "\xEF\xBB\xBF<html><body>" + text + "</body></html>",
+			expectedCharset:  "utf-8",
+			expectedMismatch: true,
+		},
+		{
+			name:             "header contradicts meta",
+			contentType:      "text/html; charset=utf-8",
+			body:             `<html><head><meta charset="windows-1251"></head><body>` + text + "</body></html>",
+			expectedCharset:  "utf-8",
+			expectedMeta:     "windows-1251",
+			expectedMismatch: true,
+		},
+		{
+			name:             "utf-8 declared for windows-1251 content",
+			contentType:      "text/html; charset=utf-8",
+			body:             "<html><body>" + encode(charmap.Windows1251, text) + "</body></html>",
+			expectedCharset:  "utf-8",
+			expectedMismatch: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := &CrawlerResult{ContentType: tt.contentType}
+			decoded := string(decodeBody(result, []byte(tt.body)))
+
+			if result.Charset != tt.expectedCharset {
+				t.Errorf("Expected charset %q, got %q", tt.expectedCharset, result.Charset)
+			}
+			if result.MetaCharset != tt.expectedMeta {
+				t.Errorf("Expected meta charset %q, got %q", tt.expectedMeta, result.MetaCharset)
+			}
+			if result.CharsetMismatch != tt.expectedMismatch {
+				t.Errorf("Expected mismatch %v, got %v (header %q, meta %q, detected %q)",
+					tt.expectedMismatch, result.CharsetMismatch, result.HeaderCharset, result.MetaCharset, result.DetectedCharset)
+			}
+			// Текст без противоречий должен быть перекодирован без искажений
+			if !tt.expectedMismatch && !strings.Contains(decoded, text) {
+				t.Errorf("Expected decoded body to contain %q, got %q", text, decoded)
+			}
+		})
+	}
+}
+
+func TestDecodeBody_Truncated(t *testing.T) {
+	text := "<html><body>Привет, мир"
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"cut inside two-byte rune", text + "!Э"[:2]},
+		{"cut inside three-byte rune", text + "€"[:2]},
+		{"cut inside four-byte rune", text + "😀"[:3]},
+		{"cut on rune boundary", text},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := &CrawlerResult{ContentType: "text/html", BodyTruncated: true}
+			decodeBody(result, []byte(tt.body))
+			if result.DetectedCharset != "utf-8" || result.Charset != "utf-8" {
+				t.Errorf("Expected utf-8, got detected %q, charset %q", result.DetectedCharset, result.Charset)
+			}
+		})
+	}
+}
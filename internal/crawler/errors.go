@@ -1,6 +1,9 @@
 package crawler
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var (
 	// ErrInvalidURL возникает, когда URL невалиден
@@ -8,4 +11,33 @@ var (
 
 	// ErrUnexpectedStatusCode возникает, когда сервер возвращает неожиданный статус код
 	ErrUnexpectedStatusCode = errors.New("unexpected status code")
+
+	// ErrBlockedByRobots возникает, когда URL запрещен правилами robots.txt
+	ErrBlockedByRobots = errors.New("blocked by robots.txt")
+
+	// ErrInvalidConfig возникает, когда конфигурация краулера содержит недопустимые значения
+	ErrInvalidConfig = errors.New("invalid crawler config")
+
+	// ErrRedirectLoop возникает, когда цепочка редиректов возвращается на уже посещенный URL
+	ErrRedirectLoop = errors.New("redirect loop")
+
+	// ErrTooManyRedirects возникает, когда цепочка редиректов длиннее Config.MaxRedirects
+	ErrTooManyRedirects = errors.New("too many redirects")
 )
+
+// StatusError возникает, когда сервер возвращает статус, отличный от 200.
+// Оборачивает ErrUnexpectedStatusCode, поэтому errors.Is продолжает работать.
+type StatusError struct {
+	URL        string
+	StatusCode int
+}
+
+// Error реализует интерфейс error
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s: %d", ErrUnexpectedStatusCode, e.StatusCode)
+}
+
+// Unwrap возвращает ErrUnexpectedStatusCode
+func (e *StatusError) Unwrap() error {
+	return ErrUnexpectedStatusCode
+}
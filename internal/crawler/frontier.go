@@ -0,0 +1,270 @@
+package crawler
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/url"
+	"os"
+	"sync"
+)
+
+// diskFrontierBatchSize — количество URL, накапливаемых в памяти перед записью на диск
+const diskFrontierBatchSize = 1024
+
+// FrontierItem представляет URL в очереди краулинга
+type FrontierItem struct {
+	// URL — нормализованный URL, ключ дедупликации и результатов краулинга
+	URL string `json:"url"`
+	// FetchURL — адрес в том виде, в котором он указан в ссылке (без фрагмента).
+	// Загружается именно он, чтобы не терять завершающий слэш и другие отличия написания.
+	// Если не задан, загружается URL.
+	FetchURL string `json:"fetch_url,omitempty"`
+	// Depth — количество переходов от начального URL. URL из sitemap получают
+	// максимальную глубину краулинга: сами они загружаются, а ссылки с них — нет.
+	// При неограниченной глубине URL из sitemap имеют глубину 0.
+	Depth int `json:"depth"`
+	// ParentURL — страница, на которой найдена ссылка
+	ParentURL string `json:"parent_url,omitempty"`
+	// Anchor — текст ссылки, по которой найден URL
+	Anchor string `json:"anchor,omitempty"`
+}
+
+// fetchURL возвращает адрес, который загружается для элемента очереди
+func (item FrontierItem) fetchURL() string {
+	if item.FetchURL != "" {
+		return item.FetchURL
+	}
+	return item.URL
+}
+
+// withoutFragment возвращает URL ссылки без фрагмента для загрузки
+func withoutFragment(link string) string {
+	u, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+	u.Fragment = ""
+	u.RawFragment = ""
+	return u.String()
+}
+
+// Frontier определяет интерфейс очереди URL краулинга.
+// Очередь не ограничена по размеру и работает в порядке FIFO.
+type Frontier interface {
+	// Push добавляет URL в конец очереди
+	Push(item FrontierItem) error
+
+	// Pop извлекает URL из начала очереди. ok == false, если очередь пуста.
+	Pop() (item FrontierItem, ok bool, err error)
+
+	// Len возвращает количество URL в очереди
+	Len() int
+
+	// Close освобождает ресурсы очереди
+	Close() error
+}
+
+// MemoryFrontier реализует Frontier в памяти
+type MemoryFrontier struct {
+	mu    sync.Mutex
+	items []FrontierItem
+	head  int
+}
+
+// NewMemoryFrontier создает новую очередь в памяти
+func NewMemoryFrontier() *MemoryFrontier {
+	return &MemoryFrontier{}
+}
+
+// Push реализует метод интерфейса Frontier
+func (f *MemoryFrontier) Push(item FrontierItem) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.items = append(f.items, item)
+	return nil
+}
+
+// Pop реализует метод интерфейса Frontier
+func (f *MemoryFrontier) Pop() (FrontierItem, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.head >= len(f.items) {
+		return FrontierItem{}, false, nil
+	}
+
+	item := f.items[f.head]
+	f.items[f.head] = FrontierItem{}
+	f.head++
+
+	// Освобождаем память под извлеченные элементы
+	if f.head == len(f.items) {
+		f.items = f.items[:0]
+		f.head = 0
+	} else if f.head > 1024 && f.head*2 > len(f.items) {
+		f.items = append([]FrontierItem(nil), f.items[f.head:]...)
+		f.head = 0
+	}
+
+	return item, true, nil
+}
+
+// Len реализует метод интерфейса Frontier
+func (f *MemoryFrontier) Len() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return len(f.items) - f.head
+}
+
+// Close реализует метод интерфейса Frontier
+func (f *MemoryFrontier) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.items = nil
+	f.head = 0
+	return nil
+}
+
+// DiskFrontier реализует Frontier в файле на диске.
+// Новые URL накапливаются в памяти и дописываются в конец файла пачками,
+// извлечение идет последовательным чтением. Когда все записанные URL
+// прочитаны, файл обрезается, поэтому его размер не растет бесконечно.
+type DiskFrontier struct {
+	mu sync.Mutex
+
+	path   string
+	writer *os.File
+	reader *os.File
+	buf    *bufio.Reader
+
+	// onDisk — количество непрочитанных URL в файле
+	onDisk int
+	// pending — URL, еще не записанные в файл (всегда новее файловых)
+	pending []FrontierItem
+}
+
+// NewDiskFrontier создает очередь во временном файле в каталоге dir.
+// Файл удаляется при вызове Close.
+func NewDiskFrontier(dir string) (*DiskFrontier, error) {
+	writer, err := os.CreateTemp(dir, "frontier-*.jsonl")
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := os.Open(writer.Name())
+	if err != nil {
+		writer.Close()
+		os.Remove(writer.Name())
+		return nil, err
+	}
+
+	return &DiskFrontier{
+		path:   writer.Name(),
+		writer: writer,
+		reader: reader,
+		buf:    bufio.NewReader(reader),
+	}, nil
+}
+
+// Push реализует метод интерфейса Frontier
+func (f *DiskFrontier) Push(item FrontierItem) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.pending = append(f.pending, item)
+	if len(f.pending) >= diskFrontierBatchSize {
+		return f.flush()
+	}
+	return nil
+}
+
+// Pop реализует метод интерфейса Frontier
+func (f *DiskFrontier) Pop() (FrontierItem, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.onDisk == 0 {
+		if len(f.pending) == 0 {
+			return FrontierItem{}, false, nil
+		}
+		item := f.pending[0]
+		f.pending = f.pending[1:]
+		return item, true, nil
+	}
+
+	line, err := f.buf.ReadBytes('\n')
+	if err != nil {
+		return FrontierItem{}, false, err
+	}
+	f.onDisk--
+
+	var item FrontierItem
+	if err := json.Unmarshal(line, &item); err != nil {
+		return FrontierItem{}, false, err
+	}
+
+	if f.onDisk == 0 {
+		if err := f.truncate(); err != nil {
+			return FrontierItem{}, false, err
+		}
+	}
+
+	return item, true, nil
+}
+
+// Len реализует метод интерфейса Frontier
+func (f *DiskFrontier) Len() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.onDisk + len(f.pending)
+}
+
+// Close реализует метод интерфейса Frontier
+func (f *DiskFrontier) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.reader.Close()
+	err := f.writer.Close()
+	if removeErr := os.Remove(f.path); err == nil {
+		err = removeErr
+	}
+	return err
+}
+
+// flush записывает накопленные URL в конец файла
+func (f *DiskFrontier) flush() error {
+	w := bufio.NewWriter(f.writer)
+	encoder := json.NewEncoder(w)
+	for _, item := range f.pending {
+		if err := encoder.Encode(item); err != nil {
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	f.onDisk += len(f.pending)
+	f.pending = f.pending[:0]
+	return nil
+}
+
+// truncate очищает полностью прочитанный файл
+func (f *DiskFrontier) truncate() error {
+	if err := f.writer.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := f.writer.Seek(0, 0); err != nil {
+		return err
+	}
+	if _, err := f.reader.Seek(0, 0); err != nil {
+		return err
+	}
+	f.buf.Reset(f.reader)
+	return nil
+}
@@ -0,0 +1,122 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestFrontier_FIFO(t *testing.T) {
+	tests := []struct {
+		name string
+		new  func(t *testing.T) Frontier
+	}{
+		{"memory", func(t *testing.T) Frontier { return NewMemoryFrontier() }},
+		{"disk", func(t *testing.T) Frontier {
+			f, err := NewDiskFrontier(t.TempDir())
+			if err != nil {
+				t.Fatalf("NewDiskFrontier failed: %v", err)
+			}
+			return f
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := tt.new(t)
+			defer f.Close()
+
+			// Чередуем добавление и извлечение, чтобы часть URL прошла через диск
+			const total = 5000
+			next := 0
+			for i := 0; i < total; i++ {
+				item := FrontierItem{URL: fmt.Sprintf("https://example.com/%d", i), Depth: i % 7, Anchor: "Текст"}
+				if err := f.Push(item); err != nil {
+					t.Fatalf("Push failed: %v", err)
+				}
+				if i%3 == 0 {
+					got, ok, err := f.Pop()
+					if err != nil || !ok {
+						t.Fatalf("Pop failed: ok=%v err=%v", ok, err)
+					}
+					if want := fmt.Sprintf("https://example.com/%d", next); got.URL != want {
+						t.Fatalf("Pop() = %s; want %s", got.URL, want)
+					}
+					next++
+				}
+			}
+
+			if f.Len() != total-next {
+				t.Errorf("Len() = %d; want %d", f.Len(), total-next)
+			}
+
+			for ; next < total; next++ {
+				got, ok, err := f.Pop()
+				if err != nil || !ok {
+					t.Fatalf("Pop failed: ok=%v err=%v", ok, err)
+				}
+				if want := fmt.Sprintf("https://example.com/%d", next); got.URL != want {
+					t.Fatalf("Pop() = %s; want %s", got.URL, want)
+				}
+				if got.Depth != next%7 || got.Anchor != "Текст" {
+					t.Fatalf("Pop() lost item fields: %+v", got)
+				}
+			}
+
+			if _, ok, _ := f.Pop(); ok {
+				t.Errorf("Expected empty frontier")
+			}
+		})
+	}
+}
+
+func TestDiskFrontier_Close(t *testing.T) {
+	dir := t.TempDir()
+	f, err := NewDiskFrontier(dir)
+	if err != nil {
+		t.Fatalf("NewDiskFrontier failed: %v", err)
+	}
+	for i := 0; i < diskFrontierBatchSize*2; i++ {
+		f.Push(FrontierItem{URL: fmt.Sprintf("https://example.com/%d", i)})
+	}
+	if err := f.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir failed: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("Expected frontier file to be removed, found %d files", len(entries))
+	}
+}
+
+func TestSiteCrawler_CrawlSite_DiskFrontier(t *testing.T) {
+	server := newSyntheticSite(t, 3000)
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.RequestDelay = 0
+	config.IgnoreSitemaps = true
+	config.FrontierDir = t.TempDir()
+
+	crawler, err := NewSiteCrawler(server.URL, config, 3000, -1)
+	if err != nil {
+		t.Fatalf("Failed to create crawler: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	result, err := crawler.CrawlSite(ctx)
+	if err != nil {
+		t.Fatalf("CrawlSite failed: %v", err)
+	}
+	// Более 1000 URL раньше не помещались в очередь; теперь ни один не теряется
+	if result.TotalPages != 3000 {
+		t.Errorf("Expected 3000 pages, got %d", result.TotalPages)
+	}
+}
@@ -0,0 +1,126 @@
+package crawler
+
+import (
+	"sort"
+	"strings"
+)
+
+// Heading представляет заголовок h1–h6 страницы
+type Heading struct {
+	// Level — уровень заголовка от 1 до 6
+	Level int
+	// Text — текст заголовка с нормализованными пробелами
+	Text string
+}
+
+// HeadingReport содержит проверки структуры заголовков страниц сайта
+type HeadingReport struct {
+	// MissingH1 содержит страницы без H1
+	MissingH1 []string
+	// MultipleH1 содержит страницы с несколькими H1
+	MultipleH1 []string
+	// DuplicateH1 содержит H1, одинаковые на нескольких страницах
+	DuplicateH1 []DuplicateHeading
+	// SkippedLevels содержит страницы, на которых пропущен уровень заголовка (например, H1 → H3)
+	SkippedLevels []SkippedHeading
+	// H1MatchesTitle содержит страницы, H1 которых совпадает с <title>
+	H1MatchesTitle []string
+}
+
+// DuplicateHeading представляет текст H1 и страницы, на которых он встречается
+type DuplicateHeading struct {
+	Text  string
+	Pages []string
+}
+
+// SkippedHeading представляет первый пропуск уровня заголовка на странице
+type SkippedHeading struct {
+	URL string
+	// From — заголовок перед пропуском
+	From Heading
+	// To — заголовок, уровень которого больше From более чем на один
+	To Heading
+}
+
+// headingLevel возвращает уровень заголовка для тега h1–h6 или 0 для других тегов
+func headingLevel(tag string) int {
+	if len(tag) == 2 && tag[0] == 'h' && tag[1] >= '1' && tag[1] <= '6' {
+		return int(tag[1] - '0')
+	}
+	return 0
+}
+
+// auditHeadings проверяет заголовки страниц: наличие и уникальность H1,
+// пропуски уровней и совпадение H1 с заголовком страницы
+func (sc *SiteCrawler) auditHeadings(result *SiteCrawlerResult) {
+	report := &result.Statistics.Headings
+	h1Pages := make(map[string][]string)
+	h1Texts := make(map[string]string)
+
+	for pageURL, page := range result.Pages {
+		if !isContentPage(page) {
+			continue
+		}
+
+		title := strings.ToLower(strings.Join(strings.Fields(page.Title), " "))
+		seen := make(map[string]bool)
+		h1Count := 0
+		matchesTitle := false
+		skipped := false
+		var previous Heading
+		for _, heading := range page.Headings {
+			if !skipped && previous.Level > 0 && heading.Level > previous.Level+1 {
+				report.SkippedLevels = append(report.SkippedLevels, SkippedHeading{URL: pageURL, From: previous, To: heading})
+				skipped = true
+			}
+			previous = heading
+
+			if heading.Level != 1 {
+				continue
+			}
+			h1Count++
+			key := strings.ToLower(heading.Text)
+			if key == "" {
+				continue
+			}
+			if !seen[key] {
+				seen[key] = true
+				h1Pages[key] = append(h1Pages[key], pageURL)
+				if _, ok := h1Texts[key]; !ok || heading.Text < h1Texts[key] {
+					h1Texts[key] = heading.Text
+				}
+			}
+			if key == title {
+				matchesTitle = true
+			}
+		}
+
+		switch {
+		case h1Count == 0:
+			report.MissingH1 = append(report.MissingH1, pageURL)
+		case h1Count > 1:
+			report.MultipleH1 = append(report.MultipleH1, pageURL)
+		}
+		if matchesTitle {
+			report.H1MatchesTitle = append(report.H1MatchesTitle, pageURL)
+		}
+	}
+
+	for key, pages := range h1Pages {
+		if len(pages) < 2 {
+			continue
+		}
+		sort.Strings(pages)
+		report.DuplicateH1 = append(report.DuplicateH1, DuplicateHeading{Text: h1Texts[key], Pages: pages})
+	}
+
+	sort.Strings(report.MissingH1)
+	sort.Strings(report.MultipleH1)
+	sort.Strings(report.H1MatchesTitle)
+	sort.Slice(report.DuplicateH1, func(i, j int) bool {
+		return report.DuplicateH1[i].Text < report.DuplicateH1[j].Text
+	})
+	sort.Slice(report.SkippedLevels, func(i, j int) bool {
+		return report.SkippedLevels[i].URL < report.SkippedLevels[j].URL
+	})
+}
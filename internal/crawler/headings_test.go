@@ -0,0 +1,69 @@
+package crawler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestSiteCrawler_CrawlSite_Headings(t *testing.T) {
+	pages := map[string]string{
+		"/": `<html><head><title>Главная</title></head><body>
+			<h1>  Главная </h1><h2>Услуги</h2><h3>Ремонт</h3>
+			<a href="/multiple">A</a> <a href="/missing">B</a> <a href="/skipped">C</a>
+		</body></html>`,
+		"/multiple": `<html><head><title>Каталог</title></head><body><h1>Каталог</h1><h1>Новинки <em>2024</em></h1></body></html>`,
+		"/missing":  `<html><head><title>Без H1</title></head><body><h2>Раздел</h2></body></html>`,
+		"/skipped":  `<html><head><title>О нас</title></head><body><h1>каталог</h1><h2>История</h2><h4>Детали</h4><h6>Сноска</h6></body></html>`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if page, ok := pages[r.URL.Path]; ok {
+			w.Write([]byte(page))
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.RequestDelay = 0
+	config.IgnoreSitemaps = true
+
+	crawler, err := NewSiteCrawler(server.URL, config, 10, 3)
+	if err != nil {
+		t.Fatalf("Failed to create crawler: %v", err)
+	}
+	result, err := crawler.CrawlSite(context.Background())
+	if err != nil {
+		t.Fatalf("CrawlSite failed: %v", err)
+	}
+
+	expectedHeadings := []Heading{{1, "Каталог"}, {1, "Новинки 2024"}}
+	if got := result.Pages[server.URL+"/multiple"].Headings; !reflect.DeepEqual(got, expectedHeadings) {
+		t.Errorf("Expected headings %+v, got %+v", expectedHeadings, got)
+	}
+
+	report := result.Statistics.Headings
+	if !reflect.DeepEqual(report.MissingH1, []string{server.URL + "/missing"}) {
+		t.Errorf("Unexpected pages without H1: %v", report.MissingH1)
+	}
+	if !reflect.DeepEqual(report.MultipleH1, []string{server.URL + "/multiple"}) {
+		t.Errorf("Unexpected pages with multiple H1: %v", report.MultipleH1)
+	}
+	if !reflect.DeepEqual(report.H1MatchesTitle, []string{server.URL + "/", server.URL + "/multiple"}) {
+		t.Errorf("Unexpected pages with H1 equal to title: %v", report.H1MatchesTitle)
+	}
+
+	expectedDuplicates := []DuplicateHeading{{Text: "Каталог", Pages: []string{server.URL + "/multiple", server.URL + "/skipped"}}}
+	if !reflect.DeepEqual(report.DuplicateH1, expectedDuplicates) {
+		t.Errorf("Expected duplicate H1 %+v, got %+v", expectedDuplicates, report.DuplicateH1)
+	}
+
+	// Для страницы сообщается только первый пропуск уровня
+	expectedSkipped := []SkippedHeading{{URL: server.URL + "/skipped", From: Heading{2, "История"}, To: Heading{4, "Детали"}}}
+	if !reflect.DeepEqual(report.SkippedLevels, expectedSkipped) {
+		t.Errorf("Expected skipped levels %+v, got %+v", expectedSkipped, report.SkippedLevels)
+	}
+}
@@ -0,0 +1,344 @@
+package crawler
+
+import (
+	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/text/language"
+)
+
+// hreflangXDefault — значение hreflang для версии страницы по умолчанию
+const hreflangXDefault = "x-default"
+
+// HreflangSource определяет, откуда получена hreflang-аннотация
+type HreflangSource string
+
+const (
+	// HreflangHTML — <link rel="alternate" hreflang> в HTML страницы
+	HreflangHTML HreflangSource = "html"
+	// HreflangHeader — HTTP-заголовок Link
+	HreflangHeader HreflangSource = "header"
+	// HreflangSitemap — <xhtml:link rel="alternate"> в sitemap
+	HreflangSitemap HreflangSource = "sitemap"
+)
+
+// HreflangLink представляет языковую версию страницы
+type HreflangLink struct {
+	// Lang — значение hreflang в том виде, в котором оно указано (ru, kk-KZ, x-default)
+	Lang string
+	// URL — абсолютный адрес языковой версии
+	URL    string
+	Source HreflangSource
+}
+
+// HreflangReport содержит проверки hreflang-аннотаций страниц сайта
+type HreflangReport struct {
+	// Pages — количество страниц с hreflang-аннотациями
+	Pages int
+	// MissingReturnLinks содержит аннотации на просканированные страницы, которые не ссылаются обратно
+	MissingReturnLinks []HreflangIssue
+	// InvalidCodes содержит аннотации с недопустимым кодом языка или региона (en-UK, en_US, eng)
+	InvalidCodes []HreflangIssue
+	// MissingXDefault содержит страницы с hreflang без x-default
+	MissingXDefault []string
+	// ToNon200 содержит аннотации на просканированные URL с ответом, отличным от 200, или с редиректом
+	ToNon200 []HreflangIssue
+	// ToNonCanonical содержит аннотации на страницы, canonical которых указывает на другой URL
+	ToNonCanonical []HreflangIssue
+	// Conflicts содержит языки, для которых на странице указано несколько разных URL
+	Conflicts []HreflangConflict
+}
+
+// HreflangIssue представляет проблемную hreflang-аннотацию страницы
+type HreflangIssue struct {
+	PageURL string
+	Lang    string
+	URL     string
+	// StatusCode — HTTP-статус URL аннотации для ToNon200
+	StatusCode int
+	// Canonical — canonical URL аннотации для ToNonCanonical
+	Canonical string
+}
+
+// HreflangConflict представляет язык, для которого страница указывает несколько URL
+type HreflangConflict struct {
+	PageURL string
+	Lang    string
+	URLs    []string
+}
+
+// isAlternateRel проверяет, что атрибут rel содержит alternate
+func isAlternateRel(rel string) bool {
+	for _, token := range strings.Fields(rel) {
+		if strings.EqualFold(token, "alternate") {
+			return true
+		}
+	}
+	return false
+}
+
+// parseHreflangLink извлекает аннотацию из элемента <link rel="alternate" hreflang>.
+// Возвращает false, если элемент не является hreflang-аннотацией.
+func parseHreflangLink(n *html.Node, base *url.URL) (HreflangLink, bool) {
+	lang := strings.TrimSpace(attrValue(n, "hreflang"))
+	if lang == "" || base == nil || !isAlternateRel(attrValue(n, "rel")) {
+		return HreflangLink{}, false
+	}
+	target, ok := resolveLocation(base.String(), attrValue(n, "href"))
+	if !ok {
+		return HreflangLink{}, false
+	}
+	return HreflangLink{Lang: lang, URL: target, Source: HreflangHTML}, true
+}
+
+// headerHreflang извлекает аннотации из заголовков Link ответа:
+// Link: <https://example.com/kk/>; rel="alternate"; hreflang="kk"
+func headerHreflang(resp *http.Response) []HreflangLink {
+	var links []HreflangLink
+	for _, value := range resp.Header.Values("Link") {
+		for _, link := range parseLinkHeader(value) {
+			lang := link.params["hreflang"]
+			if lang == "" || !isAlternateRel(link.params["rel"]) {
+				continue
+			}
+			if target, ok := resolveLocation(resp.Request.URL.String(), link.target); ok {
+				links = append(links, HreflangLink{Lang: lang, URL: target, Source: HreflangHeader})
+			}
+		}
+	}
+	return links
+}
+
+// linkHeaderValue представляет одну ссылку из заголовка Link
+type linkHeaderValue struct {
+	target string
+	// params содержит параметры ссылки с именами в нижнем регистре и значениями без кавычек
+	params map[string]string
+}
+
+// parseLinkHeader разбирает значение заголовка Link (RFC 8288).
+// Запятые и точки с запятой внутри <...> и кавычек не разделяют ссылки и параметры.
+func parseLinkHeader(value string) []linkHeaderValue {
+	var links []linkHeaderValue
+	for _, part := range splitOutside(value, ',') {
+		part = strings.TrimSpace(part)
+		if !strings.HasPrefix(part, "<") {
+			continue
+		}
+		end := strings.IndexByte(part, '>')
+		if end < 0 {
+			continue
+		}
+		link := linkHeaderValue{target: strings.TrimSpace(part[1:end]), params: make(map[string]string)}
+		for _, param := range splitOutside(part[end+1:], ';') {
+			name, val, _ := strings.Cut(param, "=")
+			name = strings.ToLower(strings.TrimSpace(name))
+			if name == "" {
+				continue
+			}
+			if _, ok := link.params[name]; !ok {
+				link.params[name] = strings.Trim(strings.TrimSpace(val), `"`)
+			}
+		}
+		links = append(links, link)
+	}
+	return links
+}
+
+// splitOutside разделяет s по sep, пропуская разделители внутри <...> и кавычек
+func splitOutside(s string, sep byte) []string {
+	var parts []string
+	inQuotes, inTarget := false, false
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '"' && !inTarget:
+			inQuotes = !inQuotes
+		case c == '<' && !inQuotes:
+			inTarget = true
+		case c == '>' && !inQuotes:
+			inTarget = false
+		case c == sep && !inQuotes && !inTarget:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// isValidHreflang проверяет код hreflang: x-default или язык ISO 639-1 с необязательными
+// письменностью ISO 15924 и регионом ISO 3166-1 alpha-2 (ru, kk-KZ, zh-Hant-TW)
+func isValidHreflang(code string) bool {
+	if strings.EqualFold(code, hreflangXDefault) {
+		return true
+	}
+	parts := strings.Split(code, "-")
+	if len(parts) > 3 || len(parts[0]) != 2 {
+		return false
+	}
+	if _, err := language.ParseBase(parts[0]); err != nil {
+		return false
+	}
+	parts = parts[1:]
+	if len(parts) > 0 && len(parts[0]) == 4 {
+		if _, err := language.ParseScript(parts[0]); err != nil {
+			return false
+		}
+		parts = parts[1:]
+	}
+	switch len(parts) {
+	case 0:
+		return true
+	case 1:
+		// Зарезервированные коды вроде UK (вместо GB) поисковые системы не принимают
+		region, err := language.ParseRegion(parts[0])
+		return err == nil && len(parts[0]) == 2 && region.IsCountry() && region.Canonicalize() == region
+	default:
+		return false
+	}
+}
+
+// addSitemapHreflang добавляет к просканированным страницам аннотации из sitemap
+func (sc *SiteCrawler) addSitemapHreflang(result *SiteCrawlerResult) {
+	for _, u := range sc.sitemapURLs {
+		page, ok := result.Pages[u.Loc]
+		if !ok {
+			continue
+		}
+		for _, alternate := range u.Alternates {
+			if target, ok := resolveLocation(u.Loc, alternate.Href); ok {
+				page.Hreflang = append(page.Hreflang, HreflangLink{Lang: alternate.Hreflang, URL: target, Source: HreflangSitemap})
+			}
+		}
+	}
+}
+
+// auditHreflang проверяет hreflang-аннотации страниц: коды языков, x-default,
+// ответные ссылки, ответ и canonical языковых версий и конфликты языков
+func (sc *SiteCrawler) auditHreflang(result *SiteCrawlerResult) {
+	sc.addSitemapHreflang(result)
+
+	// Нормализованные URL аннотаций каждой страницы по языку в нижнем регистре
+	annotations := make(map[string]map[string][]string)
+	for pageURL, page := range result.Pages {
+		if !isContentPage(page) || len(page.Hreflang) == 0 {
+			continue
+		}
+		targets := make(map[string][]string)
+		for _, link := range page.Hreflang {
+			target, err := sc.normalizer.Normalize(nil, link.URL)
+			if err != nil {
+				continue
+			}
+			lang := strings.ToLower(link.Lang)
+			if !slices.Contains(targets[lang], target) {
+				targets[lang] = append(targets[lang], target)
+			}
+		}
+		annotations[pageURL] = targets
+	}
+
+	report := &result.Hreflang
+	report.Pages = len(annotations)
+	for pageURL, page := range result.Pages {
+		targets, ok := annotations[pageURL]
+		if !ok {
+			continue
+		}
+		if _, ok := targets[hreflangXDefault]; !ok {
+			report.MissingXDefault = append(report.MissingXDefault, pageURL)
+		}
+
+		reported := make(map[string]bool)
+		for _, link := range page.Hreflang {
+			lang := strings.ToLower(link.Lang)
+			if reported[lang] {
+				continue
+			}
+			reported[lang] = true
+
+			if !isValidHreflang(link.Lang) {
+				report.InvalidCodes = append(report.InvalidCodes, HreflangIssue{PageURL: pageURL, Lang: link.Lang, URL: link.URL})
+			}
+			if len(targets[lang]) > 1 {
+				urls := append([]string(nil), targets[lang]...)
+				sort.Strings(urls)
+				report.Conflicts = append(report.Conflicts, HreflangConflict{PageURL: pageURL, Lang: link.Lang, URLs: urls})
+			}
+
+			for _, target := range targets[lang] {
+				if target == pageURL {
+					continue
+				}
+				alternate, ok := result.Pages[target]
+				if !ok {
+					continue
+				}
+				issue := HreflangIssue{PageURL: pageURL, Lang: link.Lang, URL: target}
+				if !isContentPage(alternate) {
+					issue.StatusCode = alternate.StatusCode
+					report.ToNon200 = append(report.ToNon200, issue)
+					continue
+				}
+				if canonical := sc.canonicalURL(alternate); canonical != "" && canonical != target {
+					issue.Canonical = canonical
+					report.ToNonCanonical = append(report.ToNonCanonical, issue)
+					continue
+				}
+				if !referencesPage(annotations[target], pageURL) {
+					report.MissingReturnLinks = append(report.MissingReturnLinks, issue)
+				}
+			}
+		}
+	}
+
+	sort.Strings(report.MissingXDefault)
+	for _, issues := range [][]HreflangIssue{report.MissingReturnLinks, report.InvalidCodes, report.ToNon200, report.ToNonCanonical} {
+		sort.Slice(issues, func(i, j int) bool {
+			if issues[i].PageURL != issues[j].PageURL {
+				return issues[i].PageURL < issues[j].PageURL
+			}
+			if issues[i].Lang != issues[j].Lang {
+				return issues[i].Lang < issues[j].Lang
+			}
+			return issues[i].URL < issues[j].URL
+		})
+	}
+	sort.Slice(report.Conflicts, func(i, j int) bool {
+		if report.Conflicts[i].PageURL != report.Conflicts[j].PageURL {
+			return report.Conflicts[i].PageURL < report.Conflicts[j].PageURL
+		}
+		return report.Conflicts[i].Lang < report.Conflicts[j].Lang
+	})
+}
+
+// referencesPage проверяет, что среди аннотаций страницы есть ссылка на pageURL
+func referencesPage(targets map[string][]string, pageURL string) bool {
+	for _, urls := range targets {
+		if slices.Contains(urls, pageURL) {
+			return true
+		}
+	}
+	return false
+}
+
+// enqueueHreflang добавляет в очередь внутренние языковые версии страницы,
+// чтобы проверить их ответ и ответные аннотации
+func (sc *SiteCrawler) enqueueHreflang(item FrontierItem, page *CrawlerResult) {
+	for _, link := range page.Hreflang {
+		target, err := sc.normalizer.Normalize(nil, link.URL)
+		if err != nil || target == item.URL || !sc.isValidInternalURL(target) {
+			continue
+		}
+		sc.enqueue(FrontierItem{
+			URL:       target,
+			FetchURL:  withoutFragment(link.URL),
+			Depth:     item.Depth + 1,
+			ParentURL: item.URL,
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package crawler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestIsValidHreflang(t *testing.T) {
+	tests := []struct {
+		code     string
+		expected bool
+	}{
+		{"ru", true},
+		{"kk-KZ", true},
+		{"ky-kg", true},
+		{"zh-Hant-TW", true},
+		{"x-default", true},
+		{"X-Default", true},
+		{"en-UK", false},
+		{"en_US", false},
+		{"eng", false},
+		{"jp", false},
+		{"es-419", false},
+		{"en-US-x", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			if got := isValidHreflang(tt.code); got != tt.expected {
+				t.Errorf("Expected %v for %q, got %v", tt.expected, tt.code, got)
+			}
+		})
+	}
+}
+
+func TestParseLinkHeader(t *testing.T) {
+	value := `<https://example.com/a,b>; rel="alternate"; hreflang="kk", </style.css>; rel=preload; as=style, invalid`
+
+	links := parseLinkHeader(value)
+	expected := []linkHeaderValue{
+		{target: "https://example.com/a,b", params: map[string]string{"rel": "alternate", "hreflang": "kk"}},
+		{target: "/style.css", params: map[string]string{"rel": "preload", "as": "style"}},
+	}
+	if !reflect.DeepEqual(links, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, links)
+	}
+}
+
+func TestSiteCrawler_CrawlSite_Hreflang(t *testing.T) {
+	var serverURL string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/sitemap.xml":
+			w.Write([]byte(`<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9" xmlns:xhtml="http://www.w3.org/1999/xhtml">
+				<url>
+					<loc>` + serverURL + `/de</loc>
+					<xhtml:link rel="alternate" hreflang="de" href="` + serverURL + `/de"/>
+					<xhtml:link rel="alternate" hreflang="de" href="` + serverURL + `/de-alt"/>
+					<xhtml:link rel="alternate" hreflang="en-UK" href="` + serverURL + `/en-gb"/>
+				</url>
+			</urlset>`))
+		case "/":
+			w.Write([]byte(`<html><body><a href="/ru">Русский</a></body></html>`))
+		case "/ru":
+			w.Write([]byte(`<html><head>
+				<link rel="alternate" hreflang="ru" href="/ru">
+				<link rel="alternate" hreflang="kk" href="/kk">
+				<link rel="alternate" hreflang="ky" href="/ky">
+				<link rel="alternate" hreflang="en" href="/en">
+				<link rel="alternate" hreflang="x-default" href="/ru">
+				</head><body></body></html>`))
+		case "/kk":
+			// Аннотации в заголовке Link вместо HTML
+			w.Header().Add("Link", `</ru>; rel="alternate"; hreflang="ru", </kk>; rel="alternate"; hreflang="kk"`)
+			w.Header().Add("Link", `</ru>; rel="alternate"; hreflang="x-default"`)
+			w.Write([]byte(`<html><body></body></html>`))
+		case "/ky":
+			http.Redirect(w, r, "/kk", http.StatusMovedPermanently)
+		case "/en":
+			w.Write([]byte(`<html><head><link rel="canonical" href="/en-main"></head><body></body></html>`))
+		case "/en-main", "/de", "/de-alt":
+			w.Write([]byte(`<html><body></body></html>`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	serverURL = server.URL
+
+	config := DefaultConfig()
+	config.RequestDelay = 0
+
+	crawler, err := NewSiteCrawler(server.URL, config, 20, 3)
+	if err != nil {
+		t.Fatalf("Failed to create crawler: %v", err)
+	}
+	result, err := crawler.CrawlSite(context.Background())
+	if err != nil {
+		t.Fatalf("CrawlSite failed: %v", err)
+	}
+
+	expectedKK := []HreflangLink{
+		{Lang: "ru", URL: server.URL + "/ru", Source: HreflangHeader},
+		{Lang: "kk", URL: server.URL + "/kk", Source: HreflangHeader},
+		{Lang: "x-default", URL: server.URL + "/ru", Source: HreflangHeader},
+	}
+	if got := result.Pages[server.URL+"/kk"].Hreflang; !reflect.DeepEqual(got, expectedKK) {
+		t.Errorf("Expected header annotations %+v, got %+v", expectedKK, got)
+	}
+	if got := result.Pages[server.URL+"/de"].Hreflang; len(got) != 3 || got[0].Source != HreflangSitemap {
+		t.Errorf("Expected 3 sitemap annotations for /de, got %+v", got)
+	}
+
+	report := result.Hreflang
+	if report.Pages != 3 {
+		t.Errorf("Expected 3 pages with hreflang, got %d", report.Pages)
+	}
+	if expected := []string{server.URL + "/de"}; !reflect.DeepEqual(report.MissingXDefault, expected) {
+		t.Errorf("Expected missing x-default %v, got %v", expected, report.MissingXDefault)
+	}
+
+	expectedInvalid := []HreflangIssue{{PageURL: server.URL + "/de", Lang: "en-UK", URL: server.URL + "/en-gb"}}
+	if !reflect.DeepEqual(report.InvalidCodes, expectedInvalid) {
+		t.Errorf("Expected invalid codes %+v, got %+v", expectedInvalid, report.InvalidCodes)
+	}
+
+	expectedConflicts := []HreflangConflict{{PageURL: server.URL + "/de", Lang: "de", URLs: []string{server.URL + "/de", server.URL + "/de-alt"}}}
+	if !reflect.DeepEqual(report.Conflicts, expectedConflicts) {
+		t.Errorf("Expected conflicts %+v, got %+v", expectedConflicts, report.Conflicts)
+	}
+
+	expectedNon200 := []HreflangIssue{
+		{PageURL: server.URL + "/de", Lang: "en-UK", URL: server.URL + "/en-gb", StatusCode: http.StatusNotFound},
+		{PageURL: server.URL + "/ru", Lang: "ky", URL: server.URL + "/ky", StatusCode: http.StatusMovedPermanently},
+	}
+	if !reflect.DeepEqual(report.ToNon200, expectedNon200) {
+		t.Errorf("Expected non-200 alternates %+v, got %+v", expectedNon200, report.ToNon200)
+	}
+
+	expectedNonCanonical := []HreflangIssue{{PageURL: server.URL + "/ru", Lang: "en", URL: server.URL + "/en", Canonical: server.URL + "/en-main"}}
+	if !reflect.DeepEqual(report.ToNonCanonical, expectedNonCanonical) {
+		t.Errorf("Expected non-canonical alternates %+v, got %+v", expectedNonCanonical, report.ToNonCanonical)
+	}
+
+	// /kk ссылается обратно на /ru, /de-alt не содержит аннотаций
+	expectedReturn := []HreflangIssue{{PageURL: server.URL + "/de", Lang: "de", URL: server.URL + "/de-alt"}}
+	if !reflect.DeepEqual(report.MissingReturnLinks, expectedReturn) {
+		t.Errorf("Expected missing return links %+v, got %+v", expectedReturn, report.MissingReturnLinks)
+	}
+}
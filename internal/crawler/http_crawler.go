@@ -1,87 +1,302 @@
 package crawler
 
 import (
+	"bytes"
 	"context"
+	"fmt"
 	"io"
+	"log"
 	"net/http"
 	"net/url"
+	"slices"
 	"strings"
 	"time"
 
 	"golang.org/x/net/html"
+
+	"rank-vision/internal/robots"
 )
 
 // HTTPCrawler реализует интерфейс Crawler
 type HTTPCrawler struct {
-	client *http.Client
-	config *Config
+	client  *http.Client
+	config  *Config
+	robots  *robots.Cache
+	retry   RetryPolicy
+	retries *retryBudget
+	limiter *hostLimiter
 }
 
-// NewHTTPCrawler создает новый экземпляр HTTPCrawler
+// NewHTTPCrawler создает новый экземпляр HTTPCrawler.
+// Если в конфигурации не задан Transport, создается собственный транспорт NewTransport.
 func NewHTTPCrawler(config *Config) *HTTPCrawler {
 	if config == nil {
 		config = DefaultConfig()
 	}
 
-	client := &http.Client{
-		Timeout: config.Timeout,
+	transport := config.Transport
+	if transport == nil {
+		transport = NewTransport(config)
+	}
+	return newHTTPCrawler(config, transport, nil)
+}
+
+// newHTTPCrawler создает HTTPCrawler поверх транспорта.
+// Если кэш robots.txt не передан, он создается на том же транспорте.
+func newHTTPCrawler(config *Config, transport http.RoundTripper, robotsCache *robots.Cache) *HTTPCrawler {
+	if robotsCache == nil {
+		robotsCache = robots.NewCache(&http.Client{Transport: transport, Timeout: config.Timeout}, config.UserAgent)
+	}
+
+	retry := config.RetryPolicy
+	if retry == nil {
+		retry = NewExponentialBackoff(config.MaxRetries)
 	}
 
-	return &HTTPCrawler{
-		client: client,
-		config: config,
+	c := &HTTPCrawler{
+		// Редиректы обрабатываются в CrawlPage, чтобы записать каждый переход
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   config.Timeout,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		config:  config,
+		robots:  robotsCache,
+		retry:   retry,
+		retries: newRetryBudget(config.RetryBudget),
 	}
+	c.limiter = newHostLimiter(config, c.crawlDelay)
+	return c
 }
 
-// CrawlPage реализует метод интерфейса Crawler
+// CrawlPage реализует метод интерфейса Crawler.
+// Для ответов со статусом, отличным от 200, возвращается и результат
+// с кодом ответа и заголовками, и ошибка *StatusError.
+// Редиректы (HTTP, meta refresh) записываются в RedirectChain, содержимое и метаданные
+// ответа относятся к конечному URL. Редирект скриптом только записывается в JavaScriptRedirect.
 func (c *HTTPCrawler) CrawlPage(ctx context.Context, targetURL string) (*CrawlerResult, error) {
 	if !c.IsValidURL(targetURL) {
 		return nil, ErrInvalidURL
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "GET", targetURL, nil)
-	if err != nil {
+	if err := c.checkRobots(ctx, targetURL); err != nil {
 		return nil, err
 	}
 
-	req.Header.Set("User-Agent", c.config.UserAgent)
+	maxRedirects := c.config.MaxRedirects
+	if maxRedirects <= 0 {
+		maxRedirects = defaultMaxRedirects
+	}
+
+	result := &CrawlerResult{URL: targetURL}
+	start := time.Now()
+	seen := map[string]bool{targetURL: true}
+	currentURL := targetURL
+
+	var doc *html.Node
+	for {
+		resp, timing, attempts, err := c.fetch(ctx, c.method(currentURL), currentURL)
+		if err == nil && resp.Request.Method == http.MethodHead &&
+			(resp.StatusCode == http.StatusMethodNotAllowed || resp.StatusCode == http.StatusNotImplemented) {
+			// Сервер не поддерживает HEAD: загружаем начало ресурса через GET
+			resp.Body.Close()
+			resp, timing, attempts, err = c.fetch(ctx, http.MethodGet, currentURL)
+		}
+		if err != nil {
+			return nil, err
+		}
+		applyResponse(result, resp, timing)
+		result.Attempts = attempts
+
+		body, err := readResponse(result, resp, c.maxBodySize())
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
 
-	var resp *http.Response
-	for i := 0; i < c.config.MaxRetries; i++ {
-		resp, err = c.client.Do(req)
-		if err == nil {
+		// Ищем редирект: в заголовке Location или в HTML страницы с ответом 200
+		var location string
+		redirectType, isRedirect := httpRedirectType(resp.StatusCode)
+		doc = nil
+		if isRedirect {
+			location = resp.Header.Get("Location")
+		} else if resp.StatusCode == http.StatusOK && body != nil {
+			body = decodeBody(result, body)
+			if doc, err = html.Parse(bytes.NewReader(body)); err != nil {
+				return nil, err
+			}
+			location, redirectType = clientRedirect(doc)
+			if redirectType == RedirectJavaScript {
+				if next, ok := resolveLocation(currentURL, location); ok && next != currentURL {
+					result.JavaScriptRedirect = next
+				}
+				location = ""
+			}
+		}
+
+		next, ok := resolveLocation(currentURL, location)
+		// Обновление страницы без смены адреса не считается редиректом
+		if location == "" || !ok || (!isRedirect && next == currentURL) {
 			break
 		}
-		time.Sleep(c.config.RequestDelay)
+
+		result.RedirectChain = append(result.RedirectChain, RedirectHop{
+			URL:        currentURL,
+			StatusCode: resp.StatusCode,
+			Location:   next,
+			Type:       redirectType,
+		})
+
+		switch {
+		case seen[next]:
+			err = fmt.Errorf("%w: %s", ErrRedirectLoop, next)
+		case len(result.RedirectChain) >= maxRedirects:
+			err = fmt.Errorf("%w: %d", ErrTooManyRedirects, len(result.RedirectChain))
+		default:
+			err = c.checkRobots(ctx, next)
+		}
+		if err != nil {
+			result.StatusCode = result.RedirectChain[0].StatusCode
+			result.FinalStatusCode = resp.StatusCode
+			result.DownloadTime = time.Since(start)
+			result.Error = err
+			return result, err
+		}
+
+		seen[next] = true
+		currentURL = next
 	}
-	if err != nil {
-		return nil, err
+
+	result.FinalStatusCode = result.StatusCode
+	if len(result.RedirectChain) > 0 {
+		result.StatusCode = result.RedirectChain[0].StatusCode
 	}
-	defer resp.Body.Close()
+	result.DownloadTime = time.Since(start)
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, ErrUnexpectedStatusCode
+	// Страницы с другими статусами возвращаются без разбора HTML вместе с ошибкой
+	if result.FinalStatusCode != http.StatusOK {
+		result.Error = &StatusError{URL: result.FinalURL, StatusCode: result.FinalStatusCode}
+		return result, result.Error
 	}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
+	// Ресурсы, не являющиеся HTML, сохраняются без разбора
+	if doc != nil {
+		c.parseDocument(result, doc)
 	}
+	return result, nil
+}
 
-	doc, err := html.Parse(strings.NewReader(string(body)))
-	if err != nil {
-		return nil, err
+// method возвращает HTTP-метод для загрузки URL согласно Config.ResourceFetch
+func (c *HTTPCrawler) method(targetURL string) string {
+	if c.config.ResourceFetch == ResourceFetchHead && isResourceURL(targetURL) {
+		return http.MethodHead
 	}
+	return http.MethodGet
+}
+
+// maxBodySize возвращает максимальный размер тела ответа
+func (c *HTTPCrawler) maxBodySize() int64 {
+	if c.config.MaxBodySize > 0 {
+		return c.config.MaxBodySize
+	}
+	return defaultMaxBodySize
+}
+
+// fetch выполняет запрос к URL без перехода по редиректам.
+// Запрос повторяется согласно политике повторов, пока не исчерпан бюджет хоста.
+// Возвращает ответ последней попытки и количество попыток.
+func (c *HTTPCrawler) fetch(ctx context.Context, method, targetURL string) (*http.Response, *responseTiming, int, error) {
+	for attempt := 1; ; attempt++ {
+		// Для каждой попытки создается новый запрос
+		req, err := http.NewRequestWithContext(ctx, method, targetURL, nil)
+		if err != nil {
+			return nil, nil, attempt, err
+		}
+		req.Header.Set("User-Agent", c.config.UserAgent)
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+
+		// Ждем разрешения ограничителя запросов к хосту
+		slot, err := c.limiter.acquire(ctx, req.URL)
+		if err != nil {
+			return nil, nil, attempt, err
+		}
+
+		timing := &responseTiming{}
+		resp, err := c.client.Do(timing.withTrace(req))
+		slot.observe(time.Since(timing.start), resp, err)
+		if err != nil {
+			slot.release()
+		} else {
+			// Слот освобождается после чтения тела ответа
+			resp.Body = &slotBody{ReadCloser: resp.Body, slot: slot}
+		}
+		if ctx.Err() != nil {
+			if resp != nil {
+				resp.Body.Close()
+			}
+			return nil, nil, attempt, ctx.Err()
+		}
+
+		delay, retry := c.retry.ShouldRetry(attempt, resp, err)
+		if !retry || !c.retries.take(req.URL.Host) {
+			if err != nil {
+				return nil, nil, attempt, err
+			}
+			return resp, timing, attempt, nil
+		}
 
-	result := &CrawlerResult{
-		URL: targetURL,
+		if err != nil {
+			log.Printf("Ошибка запроса %s (попытка %d): %v. Повтор через %v", targetURL, attempt, err, delay)
+		} else {
+			log.Printf("URL %s вернул статус %d (попытка %d). Повтор через %v", targetURL, resp.StatusCode, attempt, delay)
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		if err := sleepContext(ctx, delay); err != nil {
+			return nil, nil, attempt, err
+		}
 	}
+}
 
+// parseDocument извлекает из HTML-документа заголовок, мета-описание, заголовки h1–h6,
+// директивы индексации, hreflang, структурированные данные, Open Graph и Twitter Card, ссылки,
+// изображения и количество слов
+func (c *HTTPCrawler) parseDocument(result *CrawlerResult, doc *html.Node) {
 	// Извлекаем заголовок и мета-описание
+	var canonical string
+	var linkNodes, imageNodes, hreflangNodes []*html.Node
 	var f func(*html.Node)
 	f = func(n *html.Node) {
 		if n.Type == html.ElementNode {
+			for _, syntax := range []markupSyntax{microdataSyntax, rdfaSyntax} {
+				if syntax.isTopLevel(n) {
+					result.StructuredData = append(result.StructuredData, *syntax.parseItem(n))
+				}
+			}
+
 			switch n.Data {
+			case "script":
+				if strings.EqualFold(strings.TrimSpace(attrValue(n, "type")), "application/ld+json") {
+					items, err := parseJSONLD(extractText(n))
+					if err != nil {
+						result.StructuredDataErrors = append(result.StructuredDataErrors, fmt.Sprintf("invalid JSON-LD: %v", err))
+					}
+					result.StructuredData = append(result.StructuredData, items...)
+				}
+			case "img":
+				imageNodes = append(imageNodes, n)
+			case "input":
+				if strings.EqualFold(attrValue(n, "type"), "image") {
+					imageNodes = append(imageNodes, n)
+				}
+			case "h1", "h2", "h3", "h4", "h5", "h6":
+				result.Headings = append(result.Headings, Heading{
+					Level: headingLevel(n.Data),
+					Text:  strings.Join(strings.Fields(extractText(n)), " "),
+				})
 			case "title":
 				if n.FirstChild != nil {
 					result.Title = n.FirstChild.Data
@@ -97,13 +312,28 @@ func (c *HTTPCrawler) CrawlPage(ctx context.Context, targetURL string) (*Crawler
 						}
 					}
 				}
-			case "a":
+				addSocialMeta(result, n)
+				if slices.Contains(robotsMetaNames, strings.ToLower(attrValue(n, "name"))) {
+					content := attrValue(n, "content")
+					result.MetaRobots = append(result.MetaRobots, content)
+					result.Robots.add(content)
+				}
+			case "link":
+				if canonical == "" && isCanonicalRel(attrValue(n, "rel")) {
+					canonical = strings.TrimSpace(attrValue(n, "href"))
+				}
+				if hasAttr(n, "hreflang") {
+					hreflangNodes = append(hreflangNodes, n)
+				}
+			case "base":
 				for _, attr := range n.Attr {
-					if attr.Key == "href" {
-						result.Links = append(result.Links, attr.Val)
+					if attr.Key == "href" && result.BaseHref == "" {
+						result.BaseHref = strings.TrimSpace(attr.Val)
 						break
 					}
 				}
+			case "a":
+				linkNodes = append(linkNodes, n)
 			}
 		}
 		for c := n.FirstChild; c != nil; c = c.NextSibling {
@@ -112,11 +342,31 @@ func (c *HTTPCrawler) CrawlPage(ctx context.Context, targetURL string) (*Crawler
 	}
 	f(doc)
 
+	// Разрешаем и классифицируем ссылки относительно конечного URL страницы
+	document, _ := url.Parse(result.FinalURL)
+	base := documentBase(result)
+	for _, n := range linkNodes {
+		if link, ok := parseLink(n, document, base); ok {
+			result.Links = append(result.Links, link)
+		}
+	}
+	for _, n := range imageNodes {
+		if image, ok := parseImage(n, base); ok {
+			result.Images = append(result.Images, image)
+		}
+	}
+	for _, n := range hreflangNodes {
+		if link, ok := parseHreflangLink(n, base); ok {
+			result.Hreflang = append(result.Hreflang, link)
+		}
+	}
+	if ref, err := url.Parse(canonical); err == nil && canonical != "" && base != nil {
+		result.Canonical = base.ResolveReference(ref).String()
+	}
+
 	// Подсчитываем количество слов
 	text := extractText(doc)
 	result.WordCount = len(strings.Fields(text))
-
-	return result, nil
 }
 
 // IsValidURL реализует метод интерфейса Crawler
@@ -131,6 +381,35 @@ func (c *HTTPCrawler) IsValidURL(targetURL string) bool {
 	return parsedURL.Scheme != "" && parsedURL.Host != ""
 }
 
+// checkRobots проверяет, разрешен ли URL правилами robots.txt
+func (c *HTTPCrawler) checkRobots(ctx context.Context, targetURL string) error {
+	if c.config.IgnoreRobotsTxt {
+		return nil
+	}
+
+	allowed, rule, err := c.robots.Test(ctx, targetURL)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return fmt.Errorf("%w: %s", ErrBlockedByRobots, rule)
+	}
+	return nil
+}
+
+// crawlDelay возвращает Crawl-delay из robots.txt для хоста URL
+func (c *HTTPCrawler) crawlDelay(ctx context.Context, u *url.URL) time.Duration {
+	if c.config.IgnoreRobotsTxt {
+		return 0
+	}
+
+	r, err := c.robots.Get(ctx, u)
+	if err != nil {
+		return 0
+	}
+	return r.CrawlDelay(c.config.UserAgent)
+}
+
 // extractText извлекает текст из HTML-документа
 func extractText(n *html.Node) string {
 	var text string
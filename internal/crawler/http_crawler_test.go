@@ -1,10 +1,19 @@
 package crawler
 
 import (
+	"compress/gzip"
 	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
+
+	"golang.org/x/text/encoding/charmap"
 )
 
 func TestHTTPCrawler_CrawlPage(t *testing.T) {
@@ -53,8 +62,8 @@ func TestHTTPCrawler_CrawlPage(t *testing.T) {
 	if len(result.Links) != 1 {
 		t.Errorf("Expected 1 link, got %d", len(result.Links))
 	}
-	if result.Links[0] != "https://example.com" {
-		t.Errorf("Expected link 'https://example.com', got '%s'", result.Links[0])
+	if result.Links[0].Href != "https://example.com" {
+		t.Errorf("Expected link 'https://example.com', got '%s'", result.Links[0].Href)
 	}
 }
 
@@ -83,3 +92,392 @@ func TestHTTPCrawler_IsValidURL(t *testing.T) {
 		})
 	}
 }
+
+func TestHTTPCrawler_CrawlPage_RobotsTxt(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/robots.txt":
+			w.Write([]byte("User-agent: RankVision\nDisallow: /private\n"))
+		default:
+			w.Write([]byte(`<html><head><title>Page</title></head></html>`))
+		}
+	}))
+	defer server.Close()
+
+	crawler := NewHTTPCrawler(DefaultConfig())
+
+	if _, err := crawler.CrawlPage(context.Background(), server.URL+"/public"); err != nil {
+		t.Errorf("Expected /public to be crawled, got error: %v", err)
+	}
+
+	_, err := crawler.CrawlPage(context.Background(), server.URL+"/private/page")
+	if !errors.Is(err, ErrBlockedByRobots) {
+		t.Errorf("Expected ErrBlockedByRobots, got %v", err)
+	}
+
+	// С IgnoreRobotsTxt правила не применяются
+	config := DefaultConfig()
+	config.IgnoreRobotsTxt = true
+	crawler = NewHTTPCrawler(config)
+	if _, err := crawler.CrawlPage(context.Background(), server.URL+"/private/page"); err != nil {
+		t.Errorf("Expected /private/page to be crawled with IgnoreRobotsTxt, got error: %v", err)
+	}
+}
+
+func TestHTTPCrawler_CrawlPage_ResponseMetadata(t *testing.T) {
+	page := "<html><head><title>Compressed</title></head><body>" + strings.Repeat("<p>Повторяющийся текст</p>", 200) + "</body></html>"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Cache-Control", "max-age=3600")
+		if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+			w.Write([]byte(page))
+			return
+		}
+		w.Header().Set("Content-Encoding", "gzip")
+		gz := gzip.NewWriter(w)
+		gz.Write([]byte(page))
+		gz.Close()
+	}))
+	defer server.Close()
+
+	crawler := NewHTTPCrawler(DefaultConfig())
+	result, err := crawler.CrawlPage(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("CrawlPage failed: %v", err)
+	}
+
+	if result.StatusCode != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", result.StatusCode)
+	}
+	if result.FinalURL != server.URL {
+		t.Errorf("Expected final URL %s, got %s", server.URL, result.FinalURL)
+	}
+	if result.ContentType != "text/html; charset=utf-8" {
+		t.Errorf("Unexpected content type %q", result.ContentType)
+	}
+	if result.Headers.Get("Cache-Control") != "max-age=3600" {
+		t.Errorf("Expected Cache-Control header to be captured, got %v", result.Headers)
+	}
+	if result.ContentEncoding != "gzip" {
+		t.Errorf("Expected gzip encoding, got %q", result.ContentEncoding)
+	}
+	if result.UncompressedSize != int64(len(page)) {
+		t.Errorf("Expected uncompressed size %d, got %d", len(page), result.UncompressedSize)
+	}
+	if result.CompressedSize <= 0 || result.CompressedSize >= result.UncompressedSize {
+		t.Errorf("Expected compressed size below %d, got %d", result.UncompressedSize, result.CompressedSize)
+	}
+	if result.ServerIP != "127.0.0.1" {
+		t.Errorf("Expected server IP 127.0.0.1, got %q", result.ServerIP)
+	}
+	if result.Protocol != "HTTP/1.1" {
+		t.Errorf("Expected protocol HTTP/1.1, got %q", result.Protocol)
+	}
+	if result.TTFB <= 0 || result.DownloadTime < result.TTFB {
+		t.Errorf("Unexpected timings: TTFB %v, download %v", result.TTFB, result.DownloadTime)
+	}
+	if result.Title != "Compressed" {
+		t.Errorf("Expected title 'Compressed', got '%s'", result.Title)
+	}
+}
+
+func TestHTTPCrawler_CrawlPage_StatusError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Reason", "gone")
+		w.WriteHeader(http.StatusGone)
+	}))
+	defer server.Close()
+
+	crawler := NewHTTPCrawler(DefaultConfig())
+	result, err := crawler.CrawlPage(context.Background(), server.URL+"/old")
+
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) {
+		t.Fatalf("Expected *StatusError, got %v", err)
+	}
+	if statusErr.StatusCode != http.StatusGone {
+		t.Errorf("Expected status code 410 in error, got %d", statusErr.StatusCode)
+	}
+	if !errors.Is(err, ErrUnexpectedStatusCode) {
+		t.Errorf("Expected error to wrap ErrUnexpectedStatusCode")
+	}
+	if result == nil {
+		t.Fatal("Expected result for non-200 response")
+	}
+	if result.StatusCode != http.StatusGone {
+		t.Errorf("Expected result status 410, got %d", result.StatusCode)
+	}
+	if result.Headers.Get("X-Reason") != "gone" {
+		t.Errorf("Expected headers to be captured for non-200 response")
+	}
+}
+
+func TestHTTPCrawler_CrawlPage_Redirects(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/old":
+			http.Redirect(w, r, "/moved", http.StatusMovedPermanently)
+		case "/moved":
+			http.Redirect(w, r, "/meta", http.StatusFound)
+		case "/meta":
+			w.Write([]byte(`<html><head><meta http-equiv="refresh" content="0; url=/script"></head></html>`))
+		case "/script":
+			w.Write([]byte(`<html><head><title>Script</title><script>window.location.href = "/final";</script></head><body><a href="page">Ссылка</a></body></html>`))
+		case "/handler":
+			w.Write([]byte(`<html><head><title>Shop</title><script>function goCart(){ window.location.href = "/cart"; }</script></head><body><a href="page">Ссылка</a></body></html>`))
+		case "/loop-a":
+			http.Redirect(w, r, "/loop-b", http.StatusTemporaryRedirect)
+		case "/loop-b":
+			http.Redirect(w, r, "/loop-a", http.StatusPermanentRedirect)
+		}
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.RequestDelay = 0
+	crawler := NewHTTPCrawler(config)
+	result, err := crawler.CrawlPage(context.Background(), server.URL+"/old")
+	if err != nil {
+		t.Fatalf("CrawlPage failed: %v", err)
+	}
+
+	expected := []RedirectHop{
+		{URL: server.URL + "/old", StatusCode: http.StatusMovedPermanently, Location: server.URL + "/moved", Type: Redirect301},
+		{URL: server.URL + "/moved", StatusCode: http.StatusFound, Location: server.URL + "/meta", Type: Redirect302},
+		{URL: server.URL + "/meta", StatusCode: http.StatusOK, Location: server.URL + "/script", Type: RedirectMetaRefresh},
+	}
+	if len(result.RedirectChain) != len(expected) {
+		t.Fatalf("Expected %d hops, got %+v", len(expected), result.RedirectChain)
+	}
+	for i, hop := range expected {
+		if result.RedirectChain[i] != hop {
+			t.Errorf("Hop %d = %+v; want %+v", i, result.RedirectChain[i], hop)
+		}
+	}
+	if result.URL != server.URL+"/old" || result.FinalURL != server.URL+"/script" {
+		t.Errorf("Unexpected URL %s / final URL %s", result.URL, result.FinalURL)
+	}
+	if result.StatusCode != http.StatusMovedPermanently || result.FinalStatusCode != http.StatusOK {
+		t.Errorf("Unexpected status %d / final status %d", result.StatusCode, result.FinalStatusCode)
+	}
+	// Редирект скриптом записывается, но не заменяет содержимое страницы
+	if result.JavaScriptRedirect != server.URL+"/final" {
+		t.Errorf("Expected JavaScript redirect to /final, got %q", result.JavaScriptRedirect)
+	}
+	if result.Title != "Script" {
+		t.Errorf("Expected content of the final page, got title %q", result.Title)
+	}
+	if len(result.Links) != 1 || result.Links[0].URL != server.URL+"/page" {
+		t.Errorf("Expected links resolved against the final URL, got %+v", result.Links)
+	}
+
+	// Присваивание location внутри функции не является редиректом
+	result, err = crawler.CrawlPage(context.Background(), server.URL+"/handler")
+	if err != nil {
+		t.Fatalf("CrawlPage failed: %v", err)
+	}
+	if len(result.RedirectChain) != 0 || result.JavaScriptRedirect != "" || result.FinalURL != server.URL+"/handler" {
+		t.Errorf("Expected no redirect, got chain %+v, JavaScript redirect %q", result.RedirectChain, result.JavaScriptRedirect)
+	}
+	if result.Title != "Shop" || len(result.Links) != 1 {
+		t.Errorf("Expected page content to be parsed, got title %q and %d links", result.Title, len(result.Links))
+	}
+
+	// Цикл редиректов
+	result, err = crawler.CrawlPage(context.Background(), server.URL+"/loop-a")
+	if !errors.Is(err, ErrRedirectLoop) {
+		t.Fatalf("Expected ErrRedirectLoop, got %v", err)
+	}
+	if result == nil || len(result.RedirectChain) != 2 {
+		t.Fatalf("Expected loop chain of 2 hops, got %+v", result)
+	}
+
+	// Ограничение количества переходов
+	config.MaxRedirects = 2
+	crawler = NewHTTPCrawler(config)
+	result, err = crawler.CrawlPage(context.Background(), server.URL+"/old")
+	if !errors.Is(err, ErrTooManyRedirects) {
+		t.Fatalf("Expected ErrTooManyRedirects, got %v", err)
+	}
+	if result == nil || len(result.RedirectChain) != 2 {
+		t.Errorf("Expected chain cut at 2 hops, got %+v", result)
+	}
+}
+
+func TestHTTPCrawler_CrawlPage_Retry(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/flaky":
+			if requests.Add(1) < 3 {
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.Write([]byte("<html><head><title>Flaky</title></head></html>"))
+		case "/down":
+			w.Header().Set("Retry-After", "10")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.RequestDelay = 0
+	crawler := NewHTTPCrawler(config)
+	result, err := crawler.CrawlPage(context.Background(), server.URL+"/flaky")
+	if err != nil {
+		t.Fatalf("CrawlPage failed: %v", err)
+	}
+	if result.Attempts != 3 || result.Title != "Flaky" {
+		t.Errorf("Expected page after 3 attempts, got %d attempts, title %q", result.Attempts, result.Title)
+	}
+
+	// Ожидание перед повтором прерывается отменой контекста
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := crawler.CrawlPage(ctx, server.URL+"/down"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context deadline error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Expected retry wait to stop on cancellation, took %v", elapsed)
+	}
+
+	// Без бюджета повторов возвращается первый ответ
+	requests.Store(0)
+	config.RetryBudget = 1
+	crawler = NewHTTPCrawler(config)
+	result, err = crawler.CrawlPage(context.Background(), server.URL+"/flaky")
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("Expected 503 after exhausting retry budget, got %v", err)
+	}
+	if result.Attempts != 2 {
+		t.Errorf("Expected 2 attempts within budget, got %d", result.Attempts)
+	}
+}
+
+func TestHTTPCrawler_CrawlPage_Resources(t *testing.T) {
+	pdf := append([]byte("%PDF-1.7\n"), make([]byte, 1<<20)...)
+	page := "<html><head><title>Big</title></head><body>" + strings.Repeat("слово ", 1000) + "</body></html>"
+
+	var mu sync.Mutex
+	methods := make(map[string][]string)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		methods[r.URL.Path] = append(methods[r.URL.Path], r.Method)
+		mu.Unlock()
+
+		switch r.URL.Path {
+		case "/report.pdf":
+			w.Header().Set("Content-Type", "application/pdf")
+			w.Header().Set("Content-Length", strconv.Itoa(len(pdf)))
+			if r.Method == http.MethodGet {
+				w.Write(pdf)
+			}
+		case "/download":
+			w.Header().Set("Content-Type", "application/octet-stream")
+			w.Write(pdf)
+		case "/no-head.pdf":
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			w.Header().Set("Content-Type", "application/pdf")
+			w.Write(pdf)
+		case "/sniffed":
+			w.Header().Set("Content-Type", "application/octet-stream")
+			w.Write([]byte("<html><head><title>Sniffed</title></head></html>"))
+		case "/big":
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte(page))
+		}
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.RequestDelay = 0
+	config.MaxBodySize = 1024
+	crawler := NewHTTPCrawler(config)
+
+	// Частичная загрузка: тело PDF не читается целиком и не разбирается
+	for _, path := range []string{"/report.pdf", "/download"} {
+		result, err := crawler.CrawlPage(context.Background(), server.URL+path)
+		if err != nil {
+			t.Fatalf("CrawlPage(%s) failed: %v", path, err)
+		}
+		if result.MIMEType != "application/pdf" || result.StatusCode != http.StatusOK {
+			t.Errorf("%s: expected application/pdf with status 200, got %q, %d", path, result.MIMEType, result.StatusCode)
+		}
+		if result.UncompressedSize != 0 || result.BodyTruncated || result.Title != "" {
+			t.Errorf("%s: expected resource not to be read or parsed, got %+v", path, result)
+		}
+	}
+
+	result, err := crawler.CrawlPage(context.Background(), server.URL+"/sniffed")
+	if err != nil || result.MIMEType != "text/html" || result.Title != "Sniffed" {
+		t.Errorf("Expected HTML to be detected by content, got %+v, %v", result, err)
+	}
+
+	result, err = crawler.CrawlPage(context.Background(), server.URL+"/big")
+	if err != nil {
+		t.Fatalf("CrawlPage failed: %v", err)
+	}
+	if !result.BodyTruncated || result.UncompressedSize != 1024 {
+		t.Errorf("Expected body truncated to 1024 bytes, got %d (truncated %v)", result.UncompressedSize, result.BodyTruncated)
+	}
+
+	// HEAD для файлов с откатом на GET, если HEAD не поддерживается
+	config.ResourceFetch = ResourceFetchHead
+	crawler = NewHTTPCrawler(config)
+	result, err = crawler.CrawlPage(context.Background(), server.URL+"/report.pdf")
+	if err != nil {
+		t.Fatalf("CrawlPage failed: %v", err)
+	}
+	if result.ContentLength != int64(len(pdf)) || result.MIMEType != "application/pdf" {
+		t.Errorf("Expected size and type from HEAD, got %d, %q", result.ContentLength, result.MIMEType)
+	}
+	if _, err := crawler.CrawlPage(context.Background(), server.URL+"/no-head.pdf"); err != nil {
+		t.Fatalf("CrawlPage failed: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if got := strings.Join(methods["/report.pdf"], ","); got != "GET,HEAD" {
+		t.Errorf("Unexpected methods for /report.pdf: %s", got)
+	}
+	if got := strings.Join(methods["/no-head.pdf"], ","); got != "HEAD,GET" {
+		t.Errorf("Expected GET fallback after 405 on HEAD, got %s", got)
+	}
+}
+
+func TestHTTPCrawler_CrawlPage_Charset(t *testing.T) {
+	page := `<html><head><meta http-equiv="Content-Type" content="text/html; charset=windows-1251">` +
+		`<title>Главная страница</title></head><body><p>Купить слона недорого</p></body></html>`
+	body, err := charmap.Windows1251.NewEncoder().String(page)
+	if err != nil {
+		t.Fatalf("Failed to encode page: %v", err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.RequestDelay = 0
+	result, err := NewHTTPCrawler(config).CrawlPage(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("CrawlPage failed: %v", err)
+	}
+
+	if result.Title != "Главная страница" {
+		t.Errorf("Expected title to be transcoded, got %q", result.Title)
+	}
+	if result.Charset != "windows-1251" || result.DetectedCharset != "windows-1251" || result.CharsetMismatch {
+		t.Errorf("Unexpected charsets: used %q, detected %q, mismatch %v", result.Charset, result.DetectedCharset, result.CharsetMismatch)
+	}
+}
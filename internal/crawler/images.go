@@ -0,0 +1,297 @@
+package crawler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/net/html"
+)
+
+// defaultMaxImageSize — размер изображения, при превышении которого оно считается слишком большим
+const defaultMaxImageSize = 100 << 10
+
+// Image представляет изображение на странице из <img>, <picture> или <input type="image">
+type Image struct {
+	// URL — абсолютный адрес изображения: src, а если его нет или это data: URI — data-src
+	// или первый вариант из srcset
+	URL string
+	// Srcset содержит абсолютные адреса вариантов из srcset и <source> в <picture>
+	Srcset []string
+	Alt    string
+	// HasAlt — атрибут alt присутствует. Пустой alt допустим для декоративных изображений.
+	HasAlt bool
+	// Width и Height — значения атрибутов width и height
+	Width  string
+	Height string
+	// Loading — значение атрибута loading (lazy, eager)
+	Loading string
+	// DataSrc — абсолютный адрес из data-src для отложенной загрузки скриптом
+	DataSrc string
+}
+
+// ImageReport содержит инвентаризацию изображений сайта и найденные проблемы
+type ImageReport struct {
+	// Images содержит уникальные изображения по абсолютному URL
+	Images map[string]*ImageInfo
+	// MissingAlt содержит изображения без атрибута alt
+	MissingAlt []ImageUsage
+	// MissingDimensions содержит изображения без width или height (риск сдвига макета, CLS)
+	MissingDimensions []ImageUsage
+	// Oversized содержит изображения больше Config.MaxImageSize (только при Config.CheckImages)
+	Oversized []*ImageInfo
+	// Broken содержит изображения с ответом 4xx/5xx или ошибкой запроса (только при Config.CheckImages)
+	Broken []*ImageInfo
+}
+
+// ImageInfo представляет уникальное изображение сайта
+type ImageInfo struct {
+	URL string
+	// Pages содержит страницы, на которых используется изображение
+	Pages []string
+	// StatusCode, ContentLength и MIMEType заполняются при Config.CheckImages
+	StatusCode    int
+	ContentLength int64
+	MIMEType      string
+	Error         error
+}
+
+// ImageUsage представляет изображение на конкретной странице
+type ImageUsage struct {
+	PageURL  string
+	ImageURL string
+}
+
+// parseImage извлекает изображение из элемента <img> или <input type="image">.
+// Для <img> внутри <picture> добавляются варианты из элементов <source>.
+// Возвращает false, если у элемента нет адреса изображения.
+func parseImage(n *html.Node, base *url.URL) (Image, bool) {
+	image := Image{
+		Width:   strings.TrimSpace(attrValue(n, "width")),
+		Height:  strings.TrimSpace(attrValue(n, "height")),
+		Loading: strings.ToLower(strings.TrimSpace(attrValue(n, "loading"))),
+		DataSrc: resolveImageURL(base, attrValue(n, "data-src")),
+	}
+	for _, attr := range n.Attr {
+		if attr.Key == "alt" {
+			image.Alt = strings.Join(strings.Fields(attr.Val), " ")
+			image.HasAlt = true
+			break
+		}
+	}
+
+	image.Srcset = parseSrcset(base, attrValue(n, "srcset"))
+	image.Srcset = append(image.Srcset, parseSrcset(base, attrValue(n, "data-srcset"))...)
+	if n.Parent != nil && n.Parent.Type == html.ElementNode && n.Parent.Data == "picture" {
+		for c := n.Parent.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type == html.ElementNode && c.Data == "source" {
+				image.Srcset = append(image.Srcset, parseSrcset(base, attrValue(c, "srcset"))...)
+			}
+		}
+	}
+
+	image.URL = resolveImageURL(base, attrValue(n, "src"))
+	switch {
+	case image.URL != "" && !strings.HasPrefix(image.URL, "data:"):
+	case image.DataSrc != "":
+		image.URL = image.DataSrc
+	case len(image.Srcset) > 0:
+		image.URL = image.Srcset[0]
+	}
+	return image, image.URL != ""
+}
+
+// parseSrcset возвращает абсолютные адреса вариантов из значения srcset ("a.jpg 1x, b.jpg 2x")
+func parseSrcset(base *url.URL, srcset string) []string {
+	var urls []string
+	for _, candidate := range strings.Split(srcset, ",") {
+		fields := strings.Fields(candidate)
+		if len(fields) == 0 {
+			continue
+		}
+		if u := resolveImageURL(base, fields[0]); u != "" {
+			urls = append(urls, u)
+		}
+	}
+	return urls
+}
+
+// resolveImageURL разрешает адрес изображения относительно base.
+// data: URI возвращаются без изменений.
+func resolveImageURL(base *url.URL, src string) string {
+	src = strings.TrimSpace(src)
+	if src == "" {
+		return ""
+	}
+	if strings.HasPrefix(strings.ToLower(src), "data:") {
+		return src
+	}
+	ref, err := url.Parse(src)
+	if err != nil {
+		return ""
+	}
+	if base != nil {
+		ref = base.ResolveReference(ref)
+	}
+	ref.Fragment = ""
+	return ref.String()
+}
+
+// auditImages собирает уникальные изображения страниц, проверяет alt и размеры,
+// а при Config.CheckImages запрашивает каждое изображение
+func (sc *SiteCrawler) auditImages(ctx context.Context, result *SiteCrawlerResult) {
+	report := &result.Images
+	report.Images = make(map[string]*ImageInfo)
+
+	for pageURL, page := range result.Pages {
+		if !isContentPage(page) {
+			continue
+		}
+		reported := make(map[string]bool)
+		for _, image := range page.Images {
+			if strings.HasPrefix(image.URL, "data:") || reported[image.URL] {
+				continue
+			}
+			reported[image.URL] = true
+
+			info, ok := report.Images[image.URL]
+			if !ok {
+				info = &ImageInfo{URL: image.URL}
+				report.Images[image.URL] = info
+			}
+			info.Pages = append(info.Pages, pageURL)
+
+			usage := ImageUsage{PageURL: pageURL, ImageURL: image.URL}
+			if !image.HasAlt {
+				report.MissingAlt = append(report.MissingAlt, usage)
+			}
+			if image.Width == "" || image.Height == "" {
+				report.MissingDimensions = append(report.MissingDimensions, usage)
+			}
+		}
+	}
+	for _, info := range report.Images {
+		sort.Strings(info.Pages)
+	}
+
+	if sc.config.CheckImages && ctx.Err() == nil {
+		sc.checkImages(ctx, report)
+	}
+
+	for _, usages := range [][]ImageUsage{report.MissingAlt, report.MissingDimensions} {
+		sort.Slice(usages, func(i, j int) bool {
+			if usages[i].PageURL != usages[j].PageURL {
+				return usages[i].PageURL < usages[j].PageURL
+			}
+			return usages[i].ImageURL < usages[j].ImageURL
+		})
+	}
+	for _, images := range [][]*ImageInfo{report.Oversized, report.Broken} {
+		sort.Slice(images, func(i, j int) bool {
+			return images[i].URL < images[j].URL
+		})
+	}
+}
+
+// checkImages запрашивает уникальные изображения параллельно воркерами SiteCrawler
+// и находит битые и слишком большие изображения
+func (sc *SiteCrawler) checkImages(ctx context.Context, report *ImageReport) {
+	maxSize := sc.config.MaxImageSize
+	if maxSize == 0 {
+		maxSize = defaultMaxImageSize
+	}
+	log.Printf("Проверяем изображения: %d", len(report.Images))
+
+	images := make([]*ImageInfo, 0, len(report.Images))
+	for _, info := range report.Images {
+		images = append(images, info)
+	}
+	runParallel(sc.numWorkers(), images, func(info *ImageInfo) {
+		sc.crawler.checkImage(ctx, info)
+	})
+
+	for _, info := range report.Images {
+		switch {
+		case errors.Is(info.Error, context.Canceled) || errors.Is(info.Error, context.DeadlineExceeded):
+			// Проверка прервана, результат неизвестен
+		case info.Error != nil || info.StatusCode >= 400:
+			report.Broken = append(report.Broken, info)
+		case info.ContentLength > maxSize:
+			report.Oversized = append(report.Oversized, info)
+		}
+	}
+}
+
+// checkImage запрашивает изображение методом HEAD и заполняет статус, размер и тип
+func (c *HTTPCrawler) checkImage(ctx context.Context, info *ImageInfo) {
+	resp, err := c.fetchResource(ctx, http.MethodHead, info.URL)
+	if err != nil {
+		info.Error = err
+		return
+	}
+	resp.Body.Close()
+
+	info.StatusCode = resp.StatusCode
+	info.ContentLength = resp.ContentLength
+	info.MIMEType = detectMIMEType(resp.Header.Get("Content-Type"), nil)
+}
+
+// fetchResource запрашивает ресурс страницы (изображение и т.п.), следуя HTTP-редиректам.
+// Если сервер не поддерживает HEAD, запрос повторяется методом GET.
+// Тело ответа закрывает вызывающий.
+func (c *HTTPCrawler) fetchResource(ctx context.Context, method, targetURL string) (*http.Response, error) {
+	maxRedirects := c.config.MaxRedirects
+	if maxRedirects <= 0 {
+		maxRedirects = defaultMaxRedirects
+	}
+
+	currentURL := targetURL
+	for hops := 0; ; hops++ {
+		if err := c.checkRobots(ctx, currentURL); err != nil {
+			return nil, err
+		}
+		resp, _, _, err := c.fetch(ctx, method, currentURL)
+		if err == nil && method == http.MethodHead &&
+			(resp.StatusCode == http.StatusMethodNotAllowed || resp.StatusCode == http.StatusNotImplemented) {
+			resp.Body.Close()
+			resp, _, _, err = c.fetch(ctx, http.MethodGet, currentURL)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if _, ok := httpRedirectType(resp.StatusCode); ok && hops < maxRedirects {
+			if next, ok := resolveLocation(currentURL, resp.Header.Get("Location")); ok {
+				resp.Body.Close()
+				currentURL = next
+				continue
+			}
+		}
+		return resp, nil
+	}
+}
+
+// runParallel вызывает fn для каждого элемента items в workers горутинах
+func runParallel[T any](workers int, items []T, fn func(T)) {
+	queue := make(chan T)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for item := range queue {
+				fn(item)
+			}
+		}()
+	}
+	for _, item := range items {
+		queue <- item
+	}
+	close(queue)
+	wg.Wait()
+}
@@ -0,0 +1,172 @@
+package crawler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+func TestParseImage(t *testing.T) {
+	base, _ := url.Parse("https://site.com/blog/post")
+
+	tests := []struct {
+		name     string
+		html     string
+		expected Image
+		ok       bool
+	}{
+		{
+			name:     "img with alt and dimensions",
+			html:     `<img src="/logo.png" alt=" Логотип  компании " width="100" height="50">`,
+			expected: Image{URL: "https://site.com/logo.png", Alt: "Логотип компании", HasAlt: true, Width: "100", Height: "50"},
+			ok:       true,
+		},
+		{
+			name:     "decorative image with empty alt",
+			html:     `<img src="line.gif" alt="">`,
+			expected: Image{URL: "https://site.com/blog/line.gif", HasAlt: true},
+			ok:       true,
+		},
+		{
+			name: "lazy image with placeholder",
+			html: `<img src="data:image/gif;base64,R0lGOD" data-src="/photo.jpg" loading="LAZY">`,
+			expected: Image{
+				URL:     "https://site.com/photo.jpg",
+				DataSrc: "https://site.com/photo.jpg",
+				Loading: "lazy",
+			},
+			ok: true,
+		},
+		{
+			name: "picture with sources",
+			html: `<picture><source srcset="/a.webp 1x, /a@2x.webp 2x" type="image/webp"><img src="/a.jpg" srcset="/a-small.jpg 480w"></picture>`,
+			expected: Image{
+				URL:    "https://site.com/a.jpg",
+				Srcset: []string{"https://site.com/a-small.jpg", "https://site.com/a.webp", "https://site.com/a@2x.webp"},
+			},
+			ok: true,
+		},
+		{
+			name:     "srcset only",
+			html:     `<img srcset="/hero.jpg 1x">`,
+			expected: Image{URL: "https://site.com/hero.jpg", Srcset: []string{"https://site.com/hero.jpg"}},
+			ok:       true,
+		},
+		{
+			name: "image without source",
+			html: `<img alt="пусто">`,
+			ok:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := html.Parse(strings.NewReader("<html><body>" + tt.html + "</body></html>"))
+			if err != nil {
+				t.Fatalf("Failed to parse HTML: %v", err)
+			}
+			var img *html.Node
+			var f func(*html.Node)
+			f = func(n *html.Node) {
+				if n.Type == html.ElementNode && n.Data == "img" {
+					img = n
+				}
+				for c := n.FirstChild; c != nil; c = c.NextSibling {
+					f(c)
+				}
+			}
+			f(doc)
+
+			image, ok := parseImage(img, base)
+			if ok != tt.ok {
+				t.Fatalf("Expected ok=%v, got %v", tt.ok, ok)
+			}
+			if ok && !reflect.DeepEqual(image, tt.expected) {
+				t.Errorf("Expected %+v, got %+v", tt.expected, image)
+			}
+		})
+	}
+}
+
+func TestSiteCrawler_CrawlSite_Images(t *testing.T) {
+	large := make([]byte, 300<<10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			w.Write([]byte(`<html><body>
+				<img src="/logo.png" alt="Логотип" width="10" height="10">
+				<img src="/large.jpg" alt="Фото" width="10">
+				<img src="/missing.png" width="10" height="10">
+				<a href="/about">О нас</a>
+			</body></html>`))
+		case "/about":
+			w.Write([]byte(`<html><body><img src="/logo.png" alt="Логотип" width="10" height="10"><img src="/old.png"></body></html>`))
+		case "/logo.png":
+			w.Header().Set("Content-Type", "image/png")
+			w.Write([]byte("\x89PNG\r\n\x1a\n"))
+		case "/large.jpg":
+			w.Header().Set("Content-Type", "image/jpeg")
+			w.Header().Set("Content-Length", strconv.Itoa(len(large)))
+			w.Write(large)
+		case "/old.png":
+			http.Redirect(w, r, "/missing.png", http.StatusMovedPermanently)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.RequestDelay = 0
+	config.IgnoreSitemaps = true
+	config.CheckImages = true
+
+	crawler, err := NewSiteCrawler(server.URL, config, 10, 3)
+	if err != nil {
+		t.Fatalf("Failed to create crawler: %v", err)
+	}
+	result, err := crawler.CrawlSite(context.Background())
+	if err != nil {
+		t.Fatalf("CrawlSite failed: %v", err)
+	}
+
+	report := result.Images
+	if len(report.Images) != 4 {
+		t.Fatalf("Expected 4 unique images, got %d", len(report.Images))
+	}
+	logo := report.Images[server.URL+"/logo.png"]
+	if logo == nil || len(logo.Pages) != 2 || logo.StatusCode != http.StatusOK || logo.MIMEType != "image/png" {
+		t.Errorf("Unexpected logo info: %+v", logo)
+	}
+
+	expectedMissingAlt := []ImageUsage{
+		{PageURL: server.URL + "/", ImageURL: server.URL + "/missing.png"},
+		{PageURL: server.URL + "/about", ImageURL: server.URL + "/old.png"},
+	}
+	if !reflect.DeepEqual(report.MissingAlt, expectedMissingAlt) {
+		t.Errorf("Expected missing alt %+v, got %+v", expectedMissingAlt, report.MissingAlt)
+	}
+	expectedMissingDimensions := []ImageUsage{
+		{PageURL: server.URL + "/", ImageURL: server.URL + "/large.jpg"},
+		{PageURL: server.URL + "/about", ImageURL: server.URL + "/old.png"},
+	}
+	if !reflect.DeepEqual(report.MissingDimensions, expectedMissingDimensions) {
+		t.Errorf("Expected missing dimensions %+v, got %+v", expectedMissingDimensions, report.MissingDimensions)
+	}
+
+	if len(report.Oversized) != 1 || report.Oversized[0].URL != server.URL+"/large.jpg" {
+		t.Errorf("Expected /large.jpg to be oversized, got %+v", report.Oversized)
+	}
+	// Редирект на отсутствующее изображение тоже считается битым изображением
+	if len(report.Broken) != 2 || report.Broken[0].URL != server.URL+"/missing.png" ||
+		report.Broken[1].URL != server.URL+"/old.png" || report.Broken[1].StatusCode != http.StatusNotFound {
+		t.Errorf("Unexpected broken images: %+v", report.Broken)
+	}
+}
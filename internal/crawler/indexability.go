@@ -0,0 +1,189 @@
+package crawler
+
+import (
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// robotsMetaNames — значения атрибута name тега meta, директивы которых учитываются.
+// Директивы для других поисковых роботов игнорируются.
+var robotsMetaNames = []string{"robots", "googlebot"}
+
+// robotsDirectiveNames — известные директивы индексации. Используются, чтобы отличить
+// префикс робота в X-Robots-Tag ("googlebot: noindex") от директивы со значением ("max-snippet: 50").
+var robotsDirectiveNames = []string{
+	"all", "index", "follow", "noindex", "nofollow", "none", "noarchive", "nocache",
+	"nosnippet", "notranslate", "noimageindex", "indexifembedded",
+	"unavailable_after", "max-snippet", "max-image-preview", "max-video-preview",
+}
+
+// RobotsDirectives содержит директивы индексации страницы из meta robots и X-Robots-Tag
+type RobotsDirectives struct {
+	// NoIndex — страница запрещена к индексации (noindex или none)
+	NoIndex bool
+	// NoFollow — ссылки страницы запрещено обходить (nofollow или none)
+	NoFollow bool
+	// Directives содержит все директивы в нижнем регистре в порядке появления
+	Directives []string
+}
+
+// add разбирает список директив через запятую и добавляет их к уже найденным
+func (d *RobotsDirectives) add(content string) {
+	for _, directive := range strings.Split(content, ",") {
+		directive = strings.ToLower(strings.TrimSpace(directive))
+		if directive == "" {
+			continue
+		}
+		d.Directives = append(d.Directives, directive)
+		switch directive {
+		case "noindex":
+			d.NoIndex = true
+		case "nofollow":
+			d.NoFollow = true
+		case "none":
+			d.NoIndex = true
+			d.NoFollow = true
+		}
+	}
+}
+
+// addXRobotsTag добавляет директивы из значения заголовка X-Robots-Tag.
+// Значение с префиксом робота учитывается только для googlebot.
+func (d *RobotsDirectives) addXRobotsTag(value string) {
+	if agent, rest, ok := strings.Cut(value, ":"); ok {
+		agent = strings.ToLower(strings.TrimSpace(agent))
+		if !strings.Contains(agent, ",") && !slices.Contains(robotsDirectiveNames, agent) {
+			if agent != "googlebot" {
+				return
+			}
+			value = rest
+		}
+	}
+	d.add(value)
+}
+
+// isCanonicalRel проверяет, что атрибут rel тега link содержит canonical
+func isCanonicalRel(rel string) bool {
+	for _, token := range strings.Fields(rel) {
+		if strings.EqualFold(token, "canonical") {
+			return true
+		}
+	}
+	return false
+}
+
+// IndexabilityReport содержит анализ индексируемости страниц сайта
+type IndexabilityReport struct {
+	// Indexable — количество страниц, доступных для индексации
+	Indexable int
+	// NoIndex содержит страницы, запрещенные к индексации meta robots или X-Robots-Tag
+	NoIndex []string
+	// NoFollow содержит страницы, ссылки которых запрещено обходить
+	NoFollow []string
+	// Canonicalised содержит страницы, canonical которых указывает на другой URL
+	Canonicalised []CanonicalIssue
+	// SelfCanonical содержит страницы с canonical на самих себя
+	SelfCanonical []string
+	// MissingCanonical содержит страницы без canonical
+	MissingCanonical []string
+	// CanonicalToNon200 содержит canonical на просканированные URL с ответом, отличным от 200, или с редиректом
+	CanonicalToNon200 []CanonicalIssue
+	// CanonicalChains содержит canonical на страницы, которые сами канонизированы на другой URL
+	CanonicalChains []CanonicalIssue
+}
+
+// CanonicalIssue представляет страницу и ее canonical URL
+type CanonicalIssue struct {
+	URL       string
+	Canonical string
+	// StatusCode — HTTP-статус canonical URL (0, если он не просканирован)
+	StatusCode int
+	// Chain содержит последовательность canonical URL, начиная с Canonical, для цепочек
+	Chain []string
+}
+
+// auditIndexability определяет индексируемость страниц и проблемы canonical
+func (sc *SiteCrawler) auditIndexability(result *SiteCrawlerResult) {
+	report := &result.Statistics.Indexability
+	for pageURL, page := range result.Pages {
+		if !isContentPage(page) {
+			continue
+		}
+		if page.Robots.NoFollow {
+			report.NoFollow = append(report.NoFollow, pageURL)
+		}
+
+		canonical := sc.canonicalURL(page)
+		switch {
+		case canonical == "":
+			report.MissingCanonical = append(report.MissingCanonical, pageURL)
+		case canonical == pageURL:
+			report.SelfCanonical = append(report.SelfCanonical, pageURL)
+		default:
+			issue := CanonicalIssue{URL: pageURL, Canonical: page.Canonical}
+			if target, ok := result.Pages[canonical]; ok {
+				issue.StatusCode = target.StatusCode
+				if !isContentPage(target) {
+					report.CanonicalToNon200 = append(report.CanonicalToNon200, issue)
+				}
+				if chain := sc.canonicalChain(result, canonical); len(chain) > 1 {
+					issue.Chain = chain
+					report.CanonicalChains = append(report.CanonicalChains, issue)
+				}
+			}
+			report.Canonicalised = append(report.Canonicalised, issue)
+		}
+
+		if page.Robots.NoIndex {
+			report.NoIndex = append(report.NoIndex, pageURL)
+			continue
+		}
+		if canonical == "" || canonical == pageURL {
+			page.Indexable = true
+			report.Indexable++
+		}
+	}
+
+	for _, urls := range [][]string{report.NoIndex, report.NoFollow, report.SelfCanonical, report.MissingCanonical} {
+		sort.Strings(urls)
+	}
+	for _, issues := range [][]CanonicalIssue{report.Canonicalised, report.CanonicalToNon200, report.CanonicalChains} {
+		sort.Slice(issues, func(i, j int) bool {
+			return issues[i].URL < issues[j].URL
+		})
+	}
+}
+
+// canonicalURL возвращает нормализованный canonical URL страницы
+func (sc *SiteCrawler) canonicalURL(page *CrawlerResult) string {
+	if page.Canonical == "" {
+		return ""
+	}
+	canonical, err := sc.normalizer.Normalize(nil, page.Canonical)
+	if err != nil {
+		return ""
+	}
+	return canonical
+}
+
+// canonicalChain проходит по canonical, начиная со страницы pageURL, пока canonical
+// не укажет на саму страницу или на непросканированный URL. Циклы прерываются.
+func (sc *SiteCrawler) canonicalChain(result *SiteCrawlerResult, pageURL string) []string {
+	chain := []string{pageURL}
+	seen := map[string]bool{pageURL: true}
+	for {
+		page, ok := result.Pages[pageURL]
+		if !ok || page.StatusCode != http.StatusOK {
+			return chain
+		}
+		next := sc.canonicalURL(page)
+		if next == "" || seen[next] {
+			return chain
+		}
+		chain = append(chain, next)
+		seen[next] = true
+		pageURL = next
+	}
+}
@@ -0,0 +1,118 @@
+package crawler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestRobotsDirectives_AddXRobotsTag(t *testing.T) {
+	tests := []struct {
+		name     string
+		values   []string
+		noIndex  bool
+		noFollow bool
+	}{
+		{"noindex", []string{"noindex"}, true, false},
+		{"none", []string{"NONE"}, true, true},
+		{"several headers", []string{"noarchive", "nofollow"}, false, true},
+		{"googlebot prefix", []string{"googlebot: noindex, nofollow"}, true, true},
+		{"other robot prefix", []string{"bingbot: noindex"}, false, false},
+		{"directive with value", []string{"max-snippet: 50, noindex"}, true, false},
+		{"unavailable after", []string{"unavailable_after: 25 Jun 2030 15:00:00 PST"}, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var d RobotsDirectives
+			for _, value := range tt.values {
+				d.addXRobotsTag(value)
+			}
+			if d.NoIndex != tt.noIndex || d.NoFollow != tt.noFollow {
+				t.Errorf("Expected noindex=%v nofollow=%v, got %+v", tt.noIndex, tt.noFollow, d)
+			}
+		})
+	}
+}
+
+func TestSiteCrawler_CrawlSite_Indexability(t *testing.T) {
+	pages := map[string]string{
+		"/": `<html><head><title>Home</title><link rel="canonical" href="/"></head><body>
+			<a href="/noindex">A</a> <a href="/dup">B</a> <a href="/chain">C</a>
+			<a href="/to-missing">D</a> <a href="/nofollow">E</a> <a href="/header-noindex">F</a>
+		</body></html>`,
+		"/noindex":        `<html><head><meta name="robots" content="noindex, follow"></head><body></body></html>`,
+		"/dup":            `<html><head><link rel="canonical" href="/"></head><body></body></html>`,
+		"/chain":          `<html><head><link rel="canonical" href="/dup"></head><body></body></html>`,
+		"/to-missing":     `<html><head><link rel="canonical" href="/gone"></head><body></body></html>`,
+		"/nofollow":       `<html><head><meta name="googlebot" content="nofollow"></head><body><a href="/hidden">H</a></body></html>`,
+		"/header-noindex": `<html><head></head><body></body></html>`,
+		"/hidden":         `<html><head></head><body></body></html>`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, ok := pages[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		if r.URL.Path == "/header-noindex" {
+			w.Header().Set("X-Robots-Tag", "googlebot: noindex")
+		}
+		w.Write([]byte(page))
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.RequestDelay = 0
+	config.IgnoreSitemaps = true
+
+	crawler, err := NewSiteCrawler(server.URL, config, 20, 3)
+	if err != nil {
+		t.Fatalf("Failed to create crawler: %v", err)
+	}
+	result, err := crawler.CrawlSite(context.Background())
+	if err != nil {
+		t.Fatalf("CrawlSite failed: %v", err)
+	}
+
+	if _, ok := result.Pages[server.URL+"/hidden"]; ok {
+		t.Errorf("Expected links of nofollow page not to be followed")
+	}
+	if page := result.Pages[server.URL+"/gone"]; page == nil || page.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected canonical URL to be crawled, got %+v", page)
+	}
+
+	report := result.Statistics.Indexability
+	urls := func(paths ...string) []string {
+		var urls []string
+		for _, path := range paths {
+			urls = append(urls, server.URL+path)
+		}
+		return urls
+	}
+	if !reflect.DeepEqual(report.NoIndex, urls("/header-noindex", "/noindex")) {
+		t.Errorf("Unexpected noindex pages: %v", report.NoIndex)
+	}
+	if !reflect.DeepEqual(report.NoFollow, urls("/nofollow")) {
+		t.Errorf("Unexpected nofollow pages: %v", report.NoFollow)
+	}
+	if !reflect.DeepEqual(report.SelfCanonical, urls("/")) {
+		t.Errorf("Unexpected self-referencing canonicals: %v", report.SelfCanonical)
+	}
+	if len(report.Canonicalised) != 3 {
+		t.Errorf("Expected 3 canonicalised pages, got %+v", report.Canonicalised)
+	}
+	if len(report.CanonicalToNon200) != 1 || report.CanonicalToNon200[0].URL != server.URL+"/to-missing" ||
+		report.CanonicalToNon200[0].StatusCode != http.StatusNotFound {
+		t.Errorf("Unexpected canonicals to non-200: %+v", report.CanonicalToNon200)
+	}
+	if len(report.CanonicalChains) != 1 || !reflect.DeepEqual(report.CanonicalChains[0].Chain, urls("/dup", "/")) {
+		t.Errorf("Unexpected canonical chains: %+v", report.CanonicalChains)
+	}
+	// Индексируются главная и /nofollow: noindex и канонизированные страницы исключаются
+	if report.Indexable != 2 || !result.Pages[server.URL+"/"].Indexable || result.Pages[server.URL+"/dup"].Indexable {
+		t.Errorf("Expected 2 indexable pages, got %d", report.Indexable)
+	}
+}
@@ -0,0 +1,225 @@
+package crawler
+
+import (
+	"math"
+	"sort"
+)
+
+const (
+	// pageRankDamping — вероятность перехода по ссылке в модели случайного пользователя
+	pageRankDamping = 0.85
+	// pageRankTolerance — суммарное изменение PageRank за итерацию, при котором расчет останавливается
+	pageRankTolerance = 1e-9
+	// pageRankMaxIterations ограничивает количество итераций расчета PageRank
+	pageRankMaxIterations = 100
+
+	// linkGraphTopLimit — количество страниц в списках TopPages и Hubs
+	linkGraphTopLimit = 20
+	// hubFactor — во сколько раз количество исходящих ссылок хаба больше среднего по сайту
+	hubFactor = 2
+)
+
+// LinkGraphReport содержит анализ графа внутренних ссылок между HTML-страницами сайта
+type LinkGraphReport struct {
+	// Pages — количество страниц в графе
+	Pages int
+	// Edges — количество уникальных пар страниц, связанных внутренней ссылкой
+	Edges int
+	// Iterations — количество итераций расчета PageRank
+	Iterations int
+	// TopPages содержит страницы с наибольшим внутренним PageRank
+	TopPages []PageScore
+	// Hubs содержит страницы, ссылающиеся на наибольшее число внутренних страниц
+	// (не меньше чем вдвое больше среднего по сайту)
+	Hubs []PageScore
+	// Orphans содержит страницы без входящих ссылок, найденные через sitemap, canonical или hreflang
+	Orphans []PageScore
+	// WeakPages содержит страницы, на которые ведет не больше одной ссылки без nofollow.
+	// Такие страницы первыми выпадают из графа и нуждаются в дополнительных ссылках.
+	WeakPages []PageScore
+}
+
+// PageScore представляет страницу и ее показатели в графе ссылок
+type PageScore struct {
+	URL             string
+	PageRank        float64
+	Inlinks         int
+	FollowedInlinks int
+	Outlinks        int
+}
+
+// linkEdge представляет ссылку между страницами графа
+type linkEdge struct {
+	from, to int
+	// followed — хотя бы одна ссылка между страницами передает вес
+	followed bool
+}
+
+// analyzeLinkGraph строит граф внутренних ссылок, считает входящие и исходящие ссылки
+// и внутренний PageRank каждой страницы
+func (sc *SiteCrawler) analyzeLinkGraph(result *SiteCrawlerResult) {
+	var nodes []string
+	for pageURL, page := range result.Pages {
+		if isContentPage(page) {
+			nodes = append(nodes, pageURL)
+		}
+	}
+	sort.Strings(nodes)
+	index := make(map[string]int, len(nodes))
+	for i, pageURL := range nodes {
+		index[pageURL] = i
+	}
+
+	edges := sc.linkEdges(result, nodes, index)
+	report := &result.LinkGraph
+	report.Pages = len(nodes)
+	report.Edges = len(edges)
+
+	for _, edge := range edges {
+		from, to := result.Pages[nodes[edge.from]], result.Pages[nodes[edge.to]]
+		from.Outlinks++
+		to.Inlinks++
+		if edge.followed {
+			to.FollowedInlinks++
+		}
+	}
+
+	ranks, iterations := pageRank(len(nodes), edges)
+	report.Iterations = iterations
+	scores := make([]PageScore, len(nodes))
+	for i, pageURL := range nodes {
+		page := result.Pages[pageURL]
+		page.PageRank = ranks[i]
+		scores[i] = PageScore{
+			URL:             pageURL,
+			PageRank:        ranks[i],
+			Inlinks:         page.Inlinks,
+			FollowedInlinks: page.FollowedInlinks,
+			Outlinks:        page.Outlinks,
+		}
+	}
+
+	// Если начальный URL перенаправляет, главной страницей считается конечная страница редиректа
+	start := sc.start
+	if page, ok := result.Pages[start]; ok {
+		if target := sc.redirectTarget(page); target != "" {
+			start = target
+		}
+	}
+	for _, score := range scores {
+		switch {
+		case score.URL == start:
+		case score.Inlinks == 0:
+			report.Orphans = append(report.Orphans, score)
+		case score.FollowedInlinks <= 1:
+			report.WeakPages = append(report.WeakPages, score)
+		}
+	}
+
+	sort.SliceStable(scores, func(i, j int) bool {
+		return scores[i].PageRank > scores[j].PageRank
+	})
+	report.TopPages = append(report.TopPages, scores[:min(len(scores), linkGraphTopLimit)]...)
+
+	if len(nodes) > 0 {
+		average := float64(len(edges)) / float64(len(nodes))
+		for _, score := range scores {
+			if score.Outlinks > 0 && float64(score.Outlinks) >= hubFactor*average {
+				report.Hubs = append(report.Hubs, score)
+			}
+		}
+		sort.SliceStable(report.Hubs, func(i, j int) bool {
+			return report.Hubs[i].Outlinks > report.Hubs[j].Outlinks
+		})
+		report.Hubs = report.Hubs[:min(len(report.Hubs), linkGraphTopLimit)]
+	}
+}
+
+// linkEdges возвращает уникальные ссылки между страницами графа.
+// Ссылки на URL с редиректом ведут на конечную страницу, ссылки страницы на саму себя не учитываются.
+// Ссылки страниц с meta nofollow не передают вес.
+func (sc *SiteCrawler) linkEdges(result *SiteCrawlerResult, nodes []string, index map[string]int) []linkEdge {
+	var edges []linkEdge
+	for from, pageURL := range nodes {
+		page := result.Pages[pageURL]
+		targets := make(map[int]int)
+		for _, link := range page.Links {
+			if link.Type != LinkInternal {
+				continue
+			}
+			target, err := sc.normalizer.Normalize(nil, link.URL)
+			if err != nil {
+				continue
+			}
+			if linked, ok := result.Pages[target]; ok {
+				if final := sc.redirectTarget(linked); final != "" {
+					target = final
+				}
+			}
+			to, ok := index[target]
+			if !ok || to == from {
+				continue
+			}
+
+			followed := !link.NoFollow() && !page.Robots.NoFollow
+			if i, ok := targets[to]; ok {
+				edges[i].followed = edges[i].followed || followed
+				continue
+			}
+			targets[to] = len(edges)
+			edges = append(edges, linkEdge{from: from, to: to, followed: followed})
+		}
+	}
+	return edges
+}
+
+// pageRank считает PageRank степенным методом. Вес страницы делится поровну между всеми
+// ее исходящими ссылками, доля ссылок с nofollow теряется. Вес страниц без исходящих ссылок
+// распределяется между всеми страницами. Возвращает PageRank страниц и количество итераций.
+func pageRank(n int, edges []linkEdge) ([]float64, int) {
+	if n == 0 {
+		return nil, 0
+	}
+
+	outDegree := make([]int, n)
+	for _, edge := range edges {
+		outDegree[edge.from]++
+	}
+
+	ranks := make([]float64, n)
+	for i := range ranks {
+		ranks[i] = 1 / float64(n)
+	}
+	next := make([]float64, n)
+
+	iterations := 0
+	for iterations < pageRankMaxIterations {
+		iterations++
+
+		dangling := 0.0
+		for i, rank := range ranks {
+			if outDegree[i] == 0 {
+				dangling += rank
+			}
+		}
+		base := (1-pageRankDamping)/float64(n) + pageRankDamping*dangling/float64(n)
+		for i := range next {
+			next[i] = base
+		}
+		for _, edge := range edges {
+			if edge.followed {
+				next[edge.to] += pageRankDamping * ranks[edge.from] / float64(outDegree[edge.from])
+			}
+		}
+
+		delta := 0.0
+		for i := range ranks {
+			delta += math.Abs(next[i] - ranks[i])
+		}
+		ranks, next = next, ranks
+		if delta < pageRankTolerance {
+			break
+		}
+	}
+	return ranks, iterations
+}
@@ -0,0 +1,157 @@
+package crawler
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestPageRank(t *testing.T) {
+	// Классический пример: A → B, A → C, B → C, C → A
+	edges := []linkEdge{
+		{from: 0, to: 1, followed: true},
+		{from: 0, to: 2, followed: true},
+		{from: 1, to: 2, followed: true},
+		{from: 2, to: 0, followed: true},
+	}
+	ranks, iterations := pageRank(3, edges)
+	expected := []float64{0.38778, 0.21481, 0.39741}
+	for i := range expected {
+		if math.Abs(ranks[i]-expected[i]) > 1e-4 {
+			t.Errorf("PageRank[%d] = %.5f; want %.5f", i, ranks[i], expected[i])
+		}
+	}
+	if iterations == 0 || iterations >= pageRankMaxIterations {
+		t.Errorf("Expected PageRank to converge, got %d iterations", iterations)
+	}
+
+	// Ссылка с nofollow не передает вес, но ее доля теряется
+	edges[0].followed = false
+	ranks, _ = pageRank(3, edges)
+	if ranks[1] >= expected[1] {
+		t.Errorf("Expected nofollow link to lower PageRank of B, got %.5f", ranks[1])
+	}
+	if sum := ranks[0] + ranks[1] + ranks[2]; sum >= 1 {
+		t.Errorf("Expected nofollow share to be lost, got sum %.5f", sum)
+	}
+}
+
+func TestSiteCrawler_AnalyzeLinkGraph(t *testing.T) {
+	crawler, err := NewSiteCrawler("https://example.com", DefaultConfig(), 10, 3)
+	if err != nil {
+		t.Fatalf("Failed to create crawler: %v", err)
+	}
+
+	page := func(pageURL string, links ...Link) *CrawlerResult {
+		return &CrawlerResult{URL: pageURL, FinalURL: pageURL, StatusCode: 200, MIMEType: "text/html", Links: links}
+	}
+	internal := func(pageURL string, rel ...string) Link {
+		return Link{URL: pageURL, Type: LinkInternal, Rel: rel}
+	}
+	result := &SiteCrawlerResult{
+		Pages: map[string]*CrawlerResult{
+			"https://example.com/": page("https://example.com/",
+				internal("https://example.com/a"),
+				internal("https://example.com/a#top"),
+				internal("https://example.com/b", "nofollow"),
+				internal("https://example.com/old"),
+				internal("https://example.com/"),
+				Link{URL: "https://other.com/", Type: LinkExternal}),
+			"https://example.com/a": page("https://example.com/a", internal("https://example.com/c")),
+			"https://example.com/b": page("https://example.com/b"),
+			"https://example.com/c": page("https://example.com/c", internal("https://example.com/")),
+			// Найдена только через sitemap
+			"https://example.com/d": page("https://example.com/d", internal("https://example.com/a")),
+			"https://example.com/old": {
+				URL:           "https://example.com/old",
+				FinalURL:      "https://example.com/c",
+				StatusCode:    301,
+				RedirectChain: []RedirectHop{{URL: "https://example.com/old", StatusCode: 301, Location: "https://example.com/c"}},
+			},
+		},
+	}
+
+	crawler.analyzeLinkGraph(result)
+
+	report := result.LinkGraph
+	if report.Pages != 5 || report.Edges != 6 {
+		t.Errorf("Expected 5 pages and 6 edges, got %d and %d", report.Pages, report.Edges)
+	}
+
+	// Ссылки с фрагментом, на саму страницу и на редирект сводятся к уникальным страницам
+	counts := map[string][3]int{
+		"https://example.com/":  {1, 1, 3},
+		"https://example.com/a": {2, 2, 1},
+		"https://example.com/b": {1, 0, 0},
+		"https://example.com/c": {2, 2, 1},
+		"https://example.com/d": {0, 0, 1},
+	}
+	sum := 0.0
+	for pageURL, expected := range counts {
+		page := result.Pages[pageURL]
+		if got := [3]int{page.Inlinks, page.FollowedInlinks, page.Outlinks}; got != expected {
+			t.Errorf("Links of %s = %v; want %v", pageURL, got, expected)
+		}
+		sum += page.PageRank
+	}
+	if sum <= 0 || sum >= 1 {
+		t.Errorf("Expected PageRank sum below 1 because of nofollow, got %.5f", sum)
+	}
+
+	var top []string
+	for _, score := range report.TopPages {
+		top = append(top, score.URL)
+	}
+	expectedTop := []string{"https://example.com/", "https://example.com/c", "https://example.com/a", "https://example.com/b", "https://example.com/d"}
+	if !reflect.DeepEqual(top, expectedTop) {
+		t.Errorf("Expected top pages %v, got %v", expectedTop, top)
+	}
+
+	if len(report.Hubs) != 1 || report.Hubs[0].URL != "https://example.com/" {
+		t.Errorf("Expected the home page to be the only hub, got %+v", report.Hubs)
+	}
+	if len(report.Orphans) != 1 || report.Orphans[0].URL != "https://example.com/d" {
+		t.Errorf("Expected /d to be orphan, got %+v", report.Orphans)
+	}
+	if len(report.WeakPages) != 1 || report.WeakPages[0].URL != "https://example.com/b" {
+		t.Errorf("Expected /b to be weak, got %+v", report.WeakPages)
+	}
+}
+
+func TestSiteCrawler_AnalyzeLinkGraph_RedirectingStart(t *testing.T) {
+	crawler, err := NewSiteCrawler("https://example.com", DefaultConfig(), 10, 3)
+	if err != nil {
+		t.Fatalf("Failed to create crawler: %v", err)
+	}
+
+	page := func(pageURL string, links ...string) *CrawlerResult {
+		result := &CrawlerResult{URL: pageURL, FinalURL: pageURL, StatusCode: 200, MIMEType: "text/html"}
+		for _, link := range links {
+			result.Links = append(result.Links, Link{URL: link, Type: LinkInternal})
+		}
+		return result
+	}
+	result := &SiteCrawlerResult{
+		Pages: map[string]*CrawlerResult{
+			"https://example.com/": {
+				URL:           "https://example.com/",
+				FinalURL:      "https://example.com/ru",
+				StatusCode:    301,
+				RedirectChain: []RedirectHop{{URL: "https://example.com/", StatusCode: 301, Location: "https://example.com/ru"}},
+			},
+			"https://example.com/ru":   page("https://example.com/ru", "https://example.com/ru/a"),
+			"https://example.com/ru/a": page("https://example.com/ru/a", "https://example.com/ru/b"),
+			"https://example.com/ru/b": page("https://example.com/ru/b", "https://example.com/ru/a"),
+		},
+	}
+
+	crawler.analyzeLinkGraph(result)
+
+	// Конечная страница редиректа с начального URL — главная, а не сирота
+	if len(result.LinkGraph.Orphans) != 0 {
+		t.Errorf("Expected no orphans, got %+v", result.LinkGraph.Orphans)
+	}
+	if len(result.LinkGraph.WeakPages) != 1 || result.LinkGraph.WeakPages[0].URL != "https://example.com/ru/b" {
+		t.Errorf("Expected /ru/b to be weak, got %+v", result.LinkGraph.WeakPages)
+	}
+}
@@ -0,0 +1,315 @@
+package crawler
+
+import (
+	"net"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+
+	"rank-vision/internal/models"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/publicsuffix"
+)
+
+// LinkType определяет тип ссылки относительно страницы, на которой она найдена
+type LinkType string
+
+const (
+	// LinkInternal — ссылка на тот же хост
+	LinkInternal LinkType = "internal"
+	// LinkSubdomain — ссылка на другой поддомен того же домена
+	LinkSubdomain LinkType = "subdomain"
+	// LinkExternal — ссылка на другой домен
+	LinkExternal LinkType = "external"
+	// LinkMailto — ссылка mailto:
+	LinkMailto LinkType = "mailto"
+	// LinkTel — ссылка tel:
+	LinkTel LinkType = "tel"
+	// LinkJavaScript — ссылка javascript:
+	LinkJavaScript LinkType = "javascript"
+	// LinkFragment — ссылка только на фрагмент текущей страницы (#...)
+	LinkFragment LinkType = "fragment"
+	// LinkOther — ссылка с другой схемой (ftp:, data:, мессенджеры и т.п.)
+	LinkOther LinkType = "other"
+	// LinkInvalid — ссылка, которую не удалось разобрать
+	LinkInvalid LinkType = "invalid"
+)
+
+// Link представляет ссылку <a href> страницы
+type Link struct {
+	// Href — значение атрибута href как в HTML
+	Href string
+	// URL — абсолютный URL ссылки (пустой для mailto:, tel:, javascript: и невалидных ссылок)
+	URL  string
+	Type LinkType
+	// Anchor — текст ссылки с нормализованными пробелами.
+	// Для ссылки-изображения без текста используется alt изображения.
+	Anchor string
+	// Rel содержит значения атрибута rel в нижнем регистре (nofollow, sponsored, ugc, noopener)
+	Rel []string
+	// Target — значение атрибута target (_blank и т.п.)
+	Target string
+	// Title — значение атрибута title
+	Title string
+	// Image — ссылка содержит изображение
+	Image bool
+}
+
+// LinkReport содержит анализ ссылок на страницах сайта
+type LinkReport struct {
+	// Anchors содержит для каждой внутренней страницы количество ссылок на нее с каждым текстом.
+	// Ссылки без текста учитываются только в EmptyAnchors.
+	Anchors map[string]map[string]int
+	// NoFollowInternal содержит внутренние ссылки с rel nofollow, sponsored или ugc
+	NoFollowInternal []LinkUsage
+	// EmptyAnchors содержит ссылки на страницы без текста и без alt у изображения
+	EmptyAnchors []LinkUsage
+}
+
+// LinkUsage представляет ссылку на конкретной странице
+type LinkUsage struct {
+	PageURL string
+	Link    Link
+}
+
+// Backlinks возвращает внешние ссылки просканированных страниц как обратные ссылки
+// на другие сайты с текстом ссылки. Временем обнаружения считается начало сканирования.
+func (r *SiteCrawlerResult) Backlinks() []models.Backlink {
+	type key struct{ source, target, anchor string }
+	seen := make(map[key]bool)
+
+	var backlinks []models.Backlink
+	for pageURL, page := range r.Pages {
+		if !isContentPage(page) {
+			continue
+		}
+		for _, link := range page.Links {
+			k := key{pageURL, link.URL, link.Anchor}
+			if link.Type != LinkExternal || seen[k] {
+				continue
+			}
+			seen[k] = true
+			backlinks = append(backlinks, models.Backlink{
+				SourceURL:  pageURL,
+				TargetURL:  link.URL,
+				AnchorText: link.Anchor,
+				FirstSeen:  r.StartTime,
+				LastSeen:   r.StartTime,
+			})
+		}
+	}
+
+	sort.Slice(backlinks, func(i, j int) bool {
+		if backlinks[i].SourceURL != backlinks[j].SourceURL {
+			return backlinks[i].SourceURL < backlinks[j].SourceURL
+		}
+		if backlinks[i].TargetURL != backlinks[j].TargetURL {
+			return backlinks[i].TargetURL < backlinks[j].TargetURL
+		}
+		return backlinks[i].AnchorText < backlinks[j].AnchorText
+	})
+	return backlinks
+}
+
+// HasRel проверяет, что атрибут rel ссылки содержит значение value
+func (l Link) HasRel(value string) bool {
+	return slices.Contains(l.Rel, value)
+}
+
+// NoFollow проверяет, что ссылка не передает вес: rel содержит nofollow, sponsored или ugc
+func (l Link) NoFollow() bool {
+	return l.HasRel("nofollow") || l.HasRel("sponsored") || l.HasRel("ugc")
+}
+
+// parseLink извлекает ссылку из элемента <a>. Возвращает false, если у элемента нет href.
+func parseLink(n *html.Node, document, base *url.URL) (Link, bool) {
+	if !hasAttr(n, "href") {
+		return Link{}, false
+	}
+	link := ClassifyLink(document, base, attrValue(n, "href"))
+	link.Anchor = strings.Join(strings.Fields(extractText(n)), " ")
+	if rel := strings.Fields(strings.ToLower(attrValue(n, "rel"))); len(rel) > 0 {
+		link.Rel = rel
+	}
+	link.Target = strings.TrimSpace(attrValue(n, "target"))
+	link.Title = strings.Join(strings.Fields(attrValue(n, "title")), " ")
+
+	if img := findElement(n, "img"); img != nil {
+		link.Image = true
+		if link.Anchor == "" {
+			link.Anchor = strings.Join(strings.Fields(attrValue(img, "alt")), " ")
+		}
+	}
+	return link, true
+}
+
+// findElement возвращает первый вложенный элемент с тегом tag
+func findElement(n *html.Node, tag string) *html.Node {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && c.Data == tag {
+			return c
+		}
+		if found := findElement(c, tag); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// ClassifyLink разрешает href относительно base (URL документа с учетом <base href>)
+// и определяет тип ссылки по отношению к хосту документа document
+func ClassifyLink(document, base *url.URL, href string) Link {
+	if base == nil {
+		base = document
+	}
+	link := Link{Href: href}
+	trimmed := strings.TrimSpace(href)
+
+	if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+		link.Type = LinkFragment
+		if base != nil {
+			link.URL = base.String()
+		}
+		return link
+	}
+
+	parsed, err := url.Parse(trimmed)
+	if err != nil {
+		link.Type = LinkInvalid
+		return link
+	}
+
+	switch strings.ToLower(parsed.Scheme) {
+	case "mailto":
+		link.Type = LinkMailto
+		return link
+	case "tel":
+		link.Type = LinkTel
+		return link
+	case "javascript":
+		link.Type = LinkJavaScript
+		return link
+	case "", "http", "https":
+	default:
+		link.Type = LinkOther
+		return link
+	}
+
+	if base != nil {
+		parsed = base.ResolveReference(parsed)
+	}
+	if !parsed.IsAbs() || parsed.Host == "" {
+		link.Type = LinkInvalid
+		return link
+	}
+
+	link.URL = parsed.String()
+	if document == nil {
+		link.Type = LinkExternal
+		return link
+	}
+	link.Type = classifyHost(document, parsed)
+	return link
+}
+
+// classifyHost сравнивает хост ссылки с хостом документа
+func classifyHost(base, target *url.URL) LinkType {
+	baseHost := normalizeHost(base.Scheme, base.Host)
+	targetHost := normalizeHost(target.Scheme, target.Host)
+
+	switch {
+	case baseHost == targetHost:
+		return LinkInternal
+	case siteDomain(baseHost) == siteDomain(targetHost):
+		return LinkSubdomain
+	default:
+		return LinkExternal
+	}
+}
+
+// siteDomain возвращает регистрируемый домен хоста по списку публичных суффиксов
+// (sub.site.com → site.com, shop.site.com.kz → site.com.kz).
+// Для IP-адресов и хостов без регистрируемого домена возвращается сам хост.
+func siteDomain(host string) string {
+	if hostname, _, err := net.SplitHostPort(host); err == nil {
+		host = hostname
+	}
+	host = strings.Trim(host, "[]")
+	if net.ParseIP(host) != nil {
+		return host
+	}
+
+	domain, err := publicsuffix.EffectiveTLDPlusOne(host)
+	if err != nil {
+		return host
+	}
+	return domain
+}
+
+// documentBase возвращает URL, относительно которого разрешаются ссылки страницы.
+// После редиректов ссылки разрешаются относительно конечного URL.
+func documentBase(page *CrawlerResult) *url.URL {
+	document := page.URL
+	if page.FinalURL != "" {
+		document = page.FinalURL
+	}
+	base, err := url.Parse(document)
+	if err != nil {
+		return nil
+	}
+	if page.BaseHref != "" {
+		if href, err := url.Parse(page.BaseHref); err == nil {
+			base = base.ResolveReference(href)
+		}
+	}
+	return base
+}
+
+// auditLinks собирает распределение текстов ссылок по внутренним страницам,
+// внутренние ссылки с nofollow и ссылки без текста
+func (sc *SiteCrawler) auditLinks(result *SiteCrawlerResult) {
+	report := &result.Links
+	report.Anchors = make(map[string]map[string]int)
+
+	for pageURL, page := range result.Pages {
+		if !isContentPage(page) {
+			continue
+		}
+		for _, link := range page.Links {
+			switch link.Type {
+			case LinkInternal, LinkSubdomain, LinkExternal:
+			default:
+				continue
+			}
+			if link.Anchor == "" {
+				report.EmptyAnchors = append(report.EmptyAnchors, LinkUsage{PageURL: pageURL, Link: link})
+			}
+			if link.Type != LinkInternal {
+				continue
+			}
+			if link.NoFollow() {
+				report.NoFollowInternal = append(report.NoFollowInternal, LinkUsage{PageURL: pageURL, Link: link})
+			}
+
+			target, err := sc.normalizer.Normalize(nil, link.URL)
+			if err != nil || link.Anchor == "" {
+				continue
+			}
+			if report.Anchors[target] == nil {
+				report.Anchors[target] = make(map[string]int)
+			}
+			report.Anchors[target][link.Anchor]++
+		}
+	}
+
+	for _, usages := range [][]LinkUsage{report.NoFollowInternal, report.EmptyAnchors} {
+		sort.SliceStable(usages, func(i, j int) bool {
+			if usages[i].PageURL != usages[j].PageURL {
+				return usages[i].PageURL < usages[j].PageURL
+			}
+			return usages[i].Link.URL < usages[j].Link.URL
+		})
+	}
+}
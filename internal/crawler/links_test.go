@@ -0,0 +1,258 @@
+package crawler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"rank-vision/internal/models"
+
+	"golang.org/x/net/html"
+)
+
+func TestClassifyLink(t *testing.T) {
+	document, _ := url.Parse("https://site.com/blog/post.html")
+
+	tests := []struct {
+		name        string
+		href        string
+		expectedURL string
+		expected    LinkType
+	}{
+		{"root relative", "/about", "https://site.com/about", LinkInternal},
+		{"relative file", "about.html", "https://site.com/blog/about.html", LinkInternal},
+		{"parent directory", "../blog/post", "https://site.com/blog/post", LinkInternal},
+		{"query only", "?page=2", "https://site.com/blog/post.html?page=2", LinkInternal},
+		{"absolute internal", "https://site.com/contact", "https://site.com/contact", LinkInternal},
+		{"protocol relative subdomain", "//cdn.site.com/x", "https://cdn.site.com/x", LinkSubdomain},
+		{"www subdomain", "https://www.site.com/", "https://www.site.com/", LinkSubdomain},
+		{"external", "https://other.com/page", "https://other.com/page", LinkExternal},
+		{"mailto", "mailto:info@site.com", "", LinkMailto},
+		{"tel", "tel:+77001234567", "", LinkTel},
+		{"javascript", "javascript:void(0)", "", LinkJavaScript},
+		{"uppercase javascript", "JavaScript:void(0)", "", LinkJavaScript},
+		{"fragment", "#top", "https://site.com/blog/post.html", LinkFragment},
+		{"empty", "", "https://site.com/blog/post.html", LinkFragment},
+		{"other scheme", "ftp://site.com/file", "", LinkOther},
+		{"invalid", "http://[::1", "", LinkInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			link := ClassifyLink(document, nil, tt.href)
+			if link.Type != tt.expected {
+				t.Errorf("ClassifyLink(%s).Type = %s; want %s", tt.href, link.Type, tt.expected)
+			}
+			if link.URL != tt.expectedURL {
+				t.Errorf("ClassifyLink(%s).URL = %s; want %s", tt.href, link.URL, tt.expectedURL)
+			}
+		})
+	}
+}
+
+func TestClassifyLink_BaseHref(t *testing.T) {
+	document, _ := url.Parse("https://site.com/blog/post.html")
+	base, _ := url.Parse("https://site.com/docs/")
+
+	link := ClassifyLink(document, base, "intro")
+	if link.URL != "https://site.com/docs/intro" {
+		t.Errorf("Expected link resolved against <base href>, got %s", link.URL)
+	}
+	if link.Type != LinkInternal {
+		t.Errorf("Expected internal link, got %s", link.Type)
+	}
+}
+
+func TestClassifyLink_PublicSuffix(t *testing.T) {
+	tests := []struct {
+		name     string
+		document string
+		href     string
+		expected LinkType
+	}{
+		{"different sites on com.kz", "https://a.com.kz/", "https://b.com.kz/", LinkExternal},
+		{"subdomain on com.kz", "https://a.com.kz/", "https://shop.a.com.kz/", LinkSubdomain},
+		{"different sites on co.uk", "https://a.co.uk/", "https://b.co.uk/", LinkExternal},
+		{"www on co.uk", "https://www.example.co.uk/", "https://example.co.uk/", LinkSubdomain},
+		{"different sites on private suffix", "https://a.github.io/", "https://b.github.io/", LinkExternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			document, _ := url.Parse(tt.document)
+			if link := ClassifyLink(document, nil, tt.href); link.Type != tt.expected {
+				t.Errorf("ClassifyLink(%s).Type = %s; want %s", tt.href, link.Type, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseLink(t *testing.T) {
+	document, _ := url.Parse("https://site.com/blog/")
+
+	tests := []struct {
+		name     string
+		html     string
+		expected Link
+		ok       bool
+	}{
+		{
+			"text link",
+			`<a href="/about" rel="NoFollow  noopener" target="_blank" title=" О  компании ">О   нас</a>`,
+			Link{Href: "/about", URL: "https://site.com/about", Type: LinkInternal, Anchor: "О нас",
+				Rel: []string{"nofollow", "noopener"}, Target: "_blank", Title: "О компании"},
+			true,
+		},
+		{
+			"image link uses alt",
+			`<a href="https://other.com/"><span><img src="/logo.png" alt="Партнер"></span></a>`,
+			Link{Href: "https://other.com/", URL: "https://other.com/", Type: LinkExternal, Anchor: "Партнер", Image: true},
+			true,
+		},
+		{
+			"image link with text",
+			`<a href="post"><img src="/a.png" alt="Картинка"> Статья</a>`,
+			Link{Href: "post", URL: "https://site.com/blog/post", Type: LinkInternal, Anchor: "Статья", Image: true},
+			true,
+		},
+		{"without href", `<a name="top">Наверх</a>`, Link{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := html.Parse(strings.NewReader(tt.html))
+			if err != nil {
+				t.Fatalf("Failed to parse HTML: %v", err)
+			}
+			link, ok := parseLink(findElement(doc, "a"), document, document)
+			if ok != tt.ok {
+				t.Fatalf("Expected ok=%v, got %v", tt.ok, ok)
+			}
+			if !reflect.DeepEqual(link, tt.expected) {
+				t.Errorf("Expected %+v, got %+v", tt.expected, link)
+			}
+		})
+	}
+}
+
+func TestLink_NoFollow(t *testing.T) {
+	for _, rel := range []string{"nofollow", "sponsored", "ugc"} {
+		if !(Link{Rel: []string{"noopener", rel}}).NoFollow() {
+			t.Errorf("Expected rel=%s to be nofollow", rel)
+		}
+	}
+	if (Link{Rel: []string{"noopener", "noreferrer"}}).NoFollow() {
+		t.Errorf("Expected noopener link to be followed")
+	}
+}
+
+func TestSiteCrawlerResult_Backlinks(t *testing.T) {
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	external := Link{URL: "https://partner.kz/", Type: LinkExternal, Anchor: "Партнер"}
+	result := &SiteCrawlerResult{
+		StartTime: start,
+		Pages: map[string]*CrawlerResult{
+			"https://example.com/": {
+				StatusCode: http.StatusOK,
+				MIMEType:   "text/html",
+				Links: []Link{
+					external,
+					external,
+					{URL: "https://partner.kz/", Type: LinkExternal, Anchor: ""},
+					{URL: "https://example.com/about", Type: LinkInternal, Anchor: "О нас"},
+					{URL: "https://blog.example.com/", Type: LinkSubdomain, Anchor: "Блог"},
+				},
+			},
+			"https://example.com/missing": {
+				StatusCode: http.StatusNotFound,
+				MIMEType:   "text/html",
+				Links:      []Link{external},
+			},
+		},
+	}
+
+	expected := []models.Backlink{
+		{SourceURL: "https://example.com/", TargetURL: "https://partner.kz/", AnchorText: "", FirstSeen: start, LastSeen: start},
+		{SourceURL: "https://example.com/", TargetURL: "https://partner.kz/", AnchorText: "Партнер", FirstSeen: start, LastSeen: start},
+	}
+	if got := result.Backlinks(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected backlinks %+v, got %+v", expected, got)
+	}
+}
+
+func TestSiteCrawler_CrawlSite_Links(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			w.Write([]byte(`<html><body>
+				<a href="/catalog">Каталог</a>
+				<a href="/catalog"><img src="/icon.png"></a>
+				<a href="/login" rel="nofollow">Войти</a>
+				<a href="https://partner.com/" rel="sponsored">Партнер</a>
+				</body></html>`))
+		case "/login":
+			w.Write([]byte(`<html><body><a href="/catalog">Каталог</a><a href="/">Главная</a></body></html>`))
+		case "/catalog":
+			w.Write([]byte(`<html><body><a href="/">Главная</a><a href="/reviews" rel="ugc"> </a></body></html>`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.RequestDelay = 0
+	config.IgnoreSitemaps = true
+
+	crawler, err := NewSiteCrawler(server.URL, config, 10, 3)
+	if err != nil {
+		t.Fatalf("Failed to create crawler: %v", err)
+	}
+	result, err := crawler.CrawlSite(context.Background())
+	if err != nil {
+		t.Fatalf("CrawlSite failed: %v", err)
+	}
+
+	report := result.Links
+	expectedAnchors := map[string]map[string]int{
+		server.URL + "/":        {"Главная": 2},
+		server.URL + "/catalog": {"Каталог": 2},
+		server.URL + "/login":   {"Войти": 1},
+	}
+	if !reflect.DeepEqual(report.Anchors, expectedAnchors) {
+		t.Errorf("Expected anchors %v, got %v", expectedAnchors, report.Anchors)
+	}
+
+	var noFollow []string
+	for _, usage := range report.NoFollowInternal {
+		noFollow = append(noFollow, usage.PageURL+" → "+usage.Link.URL)
+	}
+	// Внешняя ссылка с rel=sponsored не является внутренней
+	expectedNoFollow := []string{
+		server.URL + "/ → " + server.URL + "/login",
+		server.URL + "/catalog → " + server.URL + "/reviews",
+	}
+	if !reflect.DeepEqual(noFollow, expectedNoFollow) {
+		t.Errorf("Expected nofollow links %v, got %v", expectedNoFollow, noFollow)
+	}
+
+	var empty []string
+	for _, usage := range report.EmptyAnchors {
+		empty = append(empty, usage.PageURL+" → "+usage.Link.URL)
+	}
+	expectedEmpty := []string{
+		server.URL + "/ → " + server.URL + "/catalog",
+		server.URL + "/catalog → " + server.URL + "/reviews",
+	}
+	if !reflect.DeepEqual(empty, expectedEmpty) {
+		t.Errorf("Expected empty anchors %v, got %v", expectedEmpty, empty)
+	}
+	if usage := report.EmptyAnchors[0]; !usage.Link.Image {
+		t.Errorf("Expected image link without alt, got %+v", usage.Link)
+	}
+}
@@ -0,0 +1,209 @@
+package crawler
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// DefaultTrackingParams содержит параметры отслеживания, удаляемые из URL по умолчанию.
+// Элементы с "*" на конце задают префикс.
+var DefaultTrackingParams = []string{
+	"utm_*",
+	"gclid",
+	"yclid",
+	"fbclid",
+	"msclkid",
+	"_openstat",
+	"mc_cid",
+	"mc_eid",
+}
+
+// NormalizationConfig настраивает нормализацию URL.
+// Нулевое значение включает все правила нормализации.
+type NormalizationConfig struct {
+	// KeepTrailingSlash сохраняет завершающий слэш в пути ("/about/" и "/about" считаются разными)
+	KeepTrailingSlash bool
+	// KeepQueryOrder сохраняет исходный порядок параметров запроса
+	KeepQueryOrder bool
+	// KeepTrackingParams отключает удаление параметров отслеживания
+	KeepTrackingParams bool
+	// TrackingParams заменяет список DefaultTrackingParams
+	TrackingParams []string
+}
+
+// URLNormalizer приводит URL к каноническому виду для дедупликации
+type URLNormalizer struct {
+	config   NormalizationConfig
+	exact    map[string]bool
+	prefixes []string
+}
+
+// NewURLNormalizer создает новый экземпляр URLNormalizer
+func NewURLNormalizer(config NormalizationConfig) *URLNormalizer {
+	params := config.TrackingParams
+	if params == nil {
+		params = DefaultTrackingParams
+	}
+
+	n := &URLNormalizer{
+		config: config,
+		exact:  make(map[string]bool),
+	}
+	for _, param := range params {
+		param = strings.ToLower(param)
+		if prefix, ok := strings.CutSuffix(param, "*"); ok {
+			n.prefixes = append(n.prefixes, prefix)
+		} else {
+			n.exact[param] = true
+		}
+	}
+	return n
+}
+
+// Resolve разрешает ссылку относительно URL документа без нормализации
+func (n *URLNormalizer) Resolve(base *url.URL, ref string) (*url.URL, error) {
+	parsed, err := url.Parse(strings.TrimSpace(ref))
+	if err != nil {
+		return nil, err
+	}
+	if base != nil {
+		parsed = base.ResolveReference(parsed)
+	}
+	return parsed, nil
+}
+
+// Normalize разрешает ссылку относительно URL документа и приводит ее к каноническому виду
+func (n *URLNormalizer) Normalize(base *url.URL, ref string) (string, error) {
+	resolved, err := n.Resolve(base, ref)
+	if err != nil {
+		return "", err
+	}
+	if !resolved.IsAbs() || resolved.Host == "" {
+		return "", fmt.Errorf("%w: %s", ErrInvalidURL, ref)
+	}
+	return n.NormalizeURL(resolved), nil
+}
+
+// NormalizeURL приводит абсолютный URL к каноническому виду
+func (n *URLNormalizer) NormalizeURL(u *url.URL) string {
+	normalized := *u
+	normalized.Scheme = strings.ToLower(normalized.Scheme)
+	normalized.Host = normalizeHost(normalized.Scheme, normalized.Host)
+	normalized.Fragment = ""
+	normalized.RawFragment = ""
+	normalized.User = nil
+
+	if normalized.Path == "" {
+		normalized.Path = "/"
+		normalized.RawPath = ""
+	}
+	if !n.config.KeepTrailingSlash && len(normalized.Path) > 1 {
+		normalized.Path = strings.TrimRight(normalized.Path, "/")
+		normalized.RawPath = strings.TrimRight(normalized.RawPath, "/")
+		if normalized.Path == "" {
+			normalized.Path = "/"
+		}
+	}
+
+	normalized.RawQuery = n.normalizeQuery(normalized.RawQuery)
+	normalized.ForceQuery = false
+
+	return normalized.String()
+}
+
+// Rules возвращает описание применяемых правил нормализации
+func (n *URLNormalizer) Rules() []string {
+	rules := []string{
+		"относительные ссылки разрешаются относительно URL страницы и <base href>",
+		"схема и хост приводятся к нижнему регистру",
+		"удаляются порты по умолчанию (:80 для http, :443 для https)",
+		"удаляются фрагменты (#...)",
+		"пустой путь заменяется на \"/\"",
+	}
+	if !n.config.KeepTrailingSlash {
+		rules = append(rules, "удаляется завершающий слэш в пути (/about/ → /about)")
+	}
+	if !n.config.KeepQueryOrder {
+		rules = append(rules, "параметры запроса сортируются по имени")
+	}
+	if !n.config.KeepTrackingParams {
+		params := make([]string, 0, len(n.exact)+len(n.prefixes))
+		for param := range n.exact {
+			params = append(params, param)
+		}
+		for _, prefix := range n.prefixes {
+			params = append(params, prefix+"*")
+		}
+		sort.Strings(params)
+		rules = append(rules, "удаляются параметры отслеживания: "+strings.Join(params, ", "))
+	}
+	return rules
+}
+
+// normalizeQuery удаляет параметры отслеживания и сортирует параметры запроса
+func (n *URLNormalizer) normalizeQuery(rawQuery string) string {
+	if rawQuery == "" {
+		return ""
+	}
+
+	var pairs []string
+	for _, pair := range strings.Split(rawQuery, "&") {
+		if pair == "" {
+			continue
+		}
+		key, _, _ := strings.Cut(pair, "=")
+		if !n.config.KeepTrackingParams && n.isTrackingParam(key) {
+			continue
+		}
+		pairs = append(pairs, pair)
+	}
+
+	if !n.config.KeepQueryOrder {
+		// Сортировка стабильна, поэтому порядок повторяющихся параметров сохраняется
+		sort.SliceStable(pairs, func(i, j int) bool {
+			ki, _, _ := strings.Cut(pairs[i], "=")
+			kj, _, _ := strings.Cut(pairs[j], "=")
+			return ki < kj
+		})
+	}
+
+	return strings.Join(pairs, "&")
+}
+
+// isTrackingParam проверяет, является ли параметр параметром отслеживания
+func (n *URLNormalizer) isTrackingParam(key string) bool {
+	if decoded, err := url.QueryUnescape(key); err == nil {
+		key = decoded
+	}
+	key = strings.ToLower(key)
+
+	if n.exact[key] {
+		return true
+	}
+	for _, prefix := range n.prefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeHost приводит хост к нижнему регистру и удаляет порт по умолчанию
+func normalizeHost(scheme, host string) string {
+	host = strings.ToLower(host)
+
+	hostname, port, err := net.SplitHostPort(host)
+	if err != nil {
+		return strings.TrimSuffix(host, ".")
+	}
+	if (scheme == "http" && port == "80") || (scheme == "https" && port == "443") {
+		if strings.Contains(hostname, ":") {
+			return "[" + hostname + "]"
+		}
+		return strings.TrimSuffix(hostname, ".")
+	}
+	return host
+}
@@ -0,0 +1,73 @@
+package crawler
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestURLNormalizer_Normalize(t *testing.T) {
+	base, _ := url.Parse("https://site.com/blog/post")
+	normalizer := NewURLNormalizer(NormalizationConfig{})
+
+	tests := []struct {
+		name     string
+		ref      string
+		expected string
+	}{
+		{"absolute path", "/about", "https://site.com/about"},
+		{"trailing slash", "/about/", "https://site.com/about"},
+		{"fragment", "/about#team", "https://site.com/about"},
+		{"uppercase scheme and host", "HTTP://Site.COM/about", "http://site.com/about"},
+		{"tracking params", "/about?utm_source=x&utm_medium=y", "https://site.com/about"},
+		{"mixed params", "/catalog?b=2&gclid=123&a=1", "https://site.com/catalog?a=1&b=2"},
+		{"default https port", "https://site.com:443/about", "https://site.com/about"},
+		{"default http port", "http://site.com:80/about", "http://site.com/about"},
+		{"custom port kept", "https://site.com:8443/about", "https://site.com:8443/about"},
+		{"relative path", "other", "https://site.com/blog/other"},
+		{"parent path", "../contact", "https://site.com/contact"},
+		{"empty path", "https://site.com", "https://site.com/"},
+		{"root", "/", "https://site.com/"},
+		{"query only", "?page=2", "https://site.com/blog/post?page=2"},
+		{"repeated params keep order", "/s?tag=b&tag=a", "https://site.com/s?tag=b&tag=a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := normalizer.Normalize(base, tt.ref)
+			if err != nil {
+				t.Fatalf("Normalize(%s) failed: %v", tt.ref, err)
+			}
+			if got != tt.expected {
+				t.Errorf("Normalize(%s) = %s; want %s", tt.ref, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestURLNormalizer_Config(t *testing.T) {
+	base, _ := url.Parse("https://site.com/")
+
+	tests := []struct {
+		name     string
+		config   NormalizationConfig
+		ref      string
+		expected string
+	}{
+		{"keep trailing slash", NormalizationConfig{KeepTrailingSlash: true}, "/about/", "https://site.com/about/"},
+		{"keep query order", NormalizationConfig{KeepQueryOrder: true}, "/s?b=1&a=2", "https://site.com/s?b=1&a=2"},
+		{"keep tracking params", NormalizationConfig{KeepTrackingParams: true}, "/s?utm_source=x", "https://site.com/s?utm_source=x"},
+		{"custom tracking params", NormalizationConfig{TrackingParams: []string{"sid", "ref_*"}}, "/s?sid=1&ref_a=2&utm_source=x", "https://site.com/s?utm_source=x"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewURLNormalizer(tt.config).Normalize(base, tt.ref)
+			if err != nil {
+				t.Fatalf("Normalize(%s) failed: %v", tt.ref, err)
+			}
+			if got != tt.expected {
+				t.Errorf("Normalize(%s) = %s; want %s", tt.ref, got, tt.expected)
+			}
+		})
+	}
+}
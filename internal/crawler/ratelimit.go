@@ -0,0 +1,247 @@
+package crawler
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultMaxConnsPerHost — количество параллельных запросов к одному хосту по умолчанию
+	defaultMaxConnsPerHost = 2
+
+	// maxThrottleDelay ограничивает интервал между запросами при автоматическом замедлении
+	maxThrottleDelay = 30 * time.Second
+
+	// minThrottleStep — минимальный интервал, с которого начинается замедление хоста без задержки
+	minThrottleStep = 100 * time.Millisecond
+
+	// throttleAlpha — вес нового наблюдения в скользящих средних времени ответа и доли ошибок
+	throttleAlpha = 0.2
+
+	// throttleErrorRate — доля ошибок 5xx, при превышении которой запросы замедляются
+	throttleErrorRate = 0.1
+
+	// throttleSlowdown — во сколько раз время ответа должно превысить базовое для замедления
+	throttleSlowdown = 2
+
+	// throttleMinLatency — время ответа, ниже которого замедление по времени ответа не применяется
+	throttleMinLatency = 200 * time.Millisecond
+)
+
+// hostLimiter ограничивает частоту и количество параллельных запросов к каждому хосту.
+// Интервал между запросами автоматически увеличивается, когда хост начинает
+// отвечать медленнее или возвращать ошибки 5xx, и возвращается к базовому при восстановлении.
+type hostLimiter struct {
+	mu    sync.Mutex
+	hosts map[string]*hostState
+
+	interval time.Duration
+	maxConns int
+	adaptive bool
+	// crawlDelay возвращает Crawl-delay из robots.txt для хоста URL
+	crawlDelay func(ctx context.Context, u *url.URL) time.Duration
+}
+
+// hostState хранит состояние ограничения запросов к одному хосту
+type hostState struct {
+	active int
+	// next — самое раннее время начала следующего запроса
+	next time.Time
+	// released закрывается и заменяется при освобождении слота
+	released chan struct{}
+
+	baseInterval time.Duration
+	interval     time.Duration
+
+	latency   time.Duration
+	baseline  time.Duration
+	errorRate float64
+	// throttled — интервал уже увеличен в текущем эпизоде перегрузки хоста
+	throttled bool
+}
+
+// hostSlot — разрешение на один запрос к хосту
+type hostSlot struct {
+	limiter *hostLimiter
+	state   *hostState
+	once    sync.Once
+}
+
+// newHostLimiter создает ограничитель запросов по конфигурации краулера
+func newHostLimiter(config *Config, crawlDelay func(ctx context.Context, u *url.URL) time.Duration) *hostLimiter {
+	interval := config.RequestDelay
+	if config.RequestsPerSecond > 0 {
+		interval = max(interval, time.Duration(float64(time.Second)/config.RequestsPerSecond))
+	}
+
+	maxConns := config.MaxConnsPerHost
+	if maxConns <= 0 {
+		maxConns = defaultMaxConnsPerHost
+	}
+	// Параллельных запросов не может быть больше, чем воркеров
+	workers := config.Workers
+	if workers <= 0 {
+		workers = defaultWorkers
+	}
+	maxConns = min(maxConns, workers)
+
+	return &hostLimiter{
+		hosts:      make(map[string]*hostState),
+		interval:   interval,
+		maxConns:   maxConns,
+		adaptive:   !config.DisableAdaptiveThrottling,
+		crawlDelay: crawlDelay,
+	}
+}
+
+// acquire ждет, пока к хосту URL можно отправить запрос.
+// Слот нужно освободить вызовом release после завершения запроса.
+func (l *hostLimiter) acquire(ctx context.Context, u *url.URL) (*hostSlot, error) {
+	host := normalizeHost(u.Scheme, u.Host)
+
+	l.mu.Lock()
+	state, ok := l.hosts[host]
+	l.mu.Unlock()
+	if !ok {
+		// Crawl-delay загружается вне блокировки, так как требует запроса robots.txt
+		interval := l.interval
+		if l.crawlDelay != nil {
+			interval = max(interval, l.crawlDelay(ctx, u))
+		}
+
+		l.mu.Lock()
+		if state, ok = l.hosts[host]; !ok {
+			state = &hostState{
+				released:     make(chan struct{}),
+				baseInterval: interval,
+				interval:     interval,
+			}
+			l.hosts[host] = state
+		}
+		l.mu.Unlock()
+	}
+
+	for {
+		l.mu.Lock()
+		if state.active < l.maxConns {
+			// Занимаем слот и время начала запроса, чтобы ожидающие не конкурировали за одно время
+			state.active++
+			start := time.Now()
+			if state.next.After(start) {
+				start = state.next
+			}
+			state.next = start.Add(state.interval)
+			l.mu.Unlock()
+
+			slot := &hostSlot{limiter: l, state: state}
+			if err := sleepContext(ctx, time.Until(start)); err != nil {
+				slot.release()
+				return nil, err
+			}
+			return slot, nil
+		}
+		released := state.released
+		l.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-released:
+		}
+	}
+}
+
+// observe учитывает результат запроса для автоматического замедления
+func (s *hostSlot) observe(elapsed time.Duration, resp *http.Response, err error) {
+	l := s.limiter
+	if !l.adaptive {
+		return
+	}
+
+	failed := 0.0
+	if err != nil || resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests {
+		failed = 1
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	state := s.state
+	state.errorRate += throttleAlpha * (failed - state.errorRate)
+	if err == nil {
+		if state.latency == 0 {
+			state.latency = elapsed
+		} else {
+			state.latency += time.Duration(throttleAlpha * float64(elapsed-state.latency))
+		}
+		if state.baseline == 0 || state.latency < state.baseline {
+			state.baseline = state.latency
+		}
+	}
+
+	overloaded := state.errorRate > throttleErrorRate ||
+		state.latency > throttleSlowdown*max(state.baseline, throttleMinLatency)
+	if overloaded {
+		// Скользящие средние остаются высокими несколько ответов подряд,
+		// поэтому интервал увеличивается один раз за эпизод перегрузки
+		if !state.throttled {
+			state.interval = min(max(state.interval*2, minThrottleStep), maxThrottleDelay)
+			state.throttled = true
+		}
+		return
+	}
+	state.throttled = false
+	if state.interval > state.baseInterval {
+		// Плавно возвращаемся к базовому интервалу
+		state.interval = max(state.interval*3/4, state.baseInterval)
+		if state.interval < minThrottleStep && state.baseInterval < minThrottleStep {
+			state.interval = state.baseInterval
+		}
+	}
+}
+
+// release освобождает слот. Повторные вызовы игнорируются.
+func (s *hostSlot) release() {
+	s.once.Do(func() {
+		l := s.limiter
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		s.state.active--
+		close(s.state.released)
+		s.state.released = make(chan struct{})
+	})
+}
+
+// slotBody освобождает слот хоста после закрытия тела ответа
+type slotBody struct {
+	io.ReadCloser
+	slot *hostSlot
+}
+
+// Close закрывает тело ответа и освобождает слот
+func (b *slotBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.slot.release()
+	return err
+}
+
+// sleepContext ждет d или отмены контекста
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
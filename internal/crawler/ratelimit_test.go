@@ -0,0 +1,167 @@
+package crawler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestHostLimiter_Interval(t *testing.T) {
+	config := DefaultConfig()
+	config.RequestDelay = 0
+	config.RequestsPerSecond = 20
+	limiter := newHostLimiter(config, nil)
+
+	u, _ := url.Parse("https://example.com/")
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		slot, err := limiter.acquire(context.Background(), u)
+		if err != nil {
+			t.Fatalf("acquire failed: %v", err)
+		}
+		slot.release()
+	}
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Errorf("Expected 4 requests at 20 rps to take at least 150ms, took %v", elapsed)
+	}
+
+	// Другой хост ограничивается независимо
+	other, _ := url.Parse("https://other.com/")
+	start = time.Now()
+	slot, err := limiter.acquire(context.Background(), other)
+	if err != nil {
+		t.Fatalf("acquire failed: %v", err)
+	}
+	slot.release()
+	if elapsed := time.Since(start); elapsed > 20*time.Millisecond {
+		t.Errorf("Expected first request to another host without delay, took %v", elapsed)
+	}
+}
+
+func TestHostLimiter_CrawlDelay(t *testing.T) {
+	config := DefaultConfig()
+	config.RequestDelay = 10 * time.Millisecond
+	limiter := newHostLimiter(config, func(ctx context.Context, u *url.URL) time.Duration {
+		return 100 * time.Millisecond
+	})
+
+	u, _ := url.Parse("https://example.com/")
+	start := time.Now()
+	for i := 0; i < 2; i++ {
+		slot, err := limiter.acquire(context.Background(), u)
+		if err != nil {
+			t.Fatalf("acquire failed: %v", err)
+		}
+		slot.release()
+	}
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Errorf("Expected Crawl-delay of 100ms between requests, took %v", elapsed)
+	}
+}
+
+func TestHostLimiter_MaxConnsPerHost(t *testing.T) {
+	config := DefaultConfig()
+	config.RequestDelay = 0
+	config.MaxConnsPerHost = 2
+	limiter := newHostLimiter(config, nil)
+
+	u, _ := url.Parse("https://example.com/")
+	first, _ := limiter.acquire(context.Background(), u)
+	second, _ := limiter.acquire(context.Background(), u)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := limiter.acquire(ctx, u); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected third acquire to wait for a free slot, got %v", err)
+	}
+
+	acquired := make(chan struct{})
+	go func() {
+		slot, err := limiter.acquire(context.Background(), u)
+		if err == nil {
+			slot.release()
+		}
+		close(acquired)
+	}()
+	first.release()
+	first.release() // повторное освобождение игнорируется
+
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("Expected acquire to proceed after release")
+	}
+	second.release()
+}
+
+func TestHostLimiter_AdaptiveThrottling(t *testing.T) {
+	config := DefaultConfig()
+	config.RequestDelay = 0
+	u, _ := url.Parse("https://example.com/")
+
+	newSlot := func(config *Config) *hostSlot {
+		slot, err := newHostLimiter(config, nil).acquire(context.Background(), u)
+		if err != nil {
+			t.Fatalf("acquire failed: %v", err)
+		}
+		return slot
+	}
+	observe := func(slot *hostSlot, status int, elapsed time.Duration) time.Duration {
+		slot.observe(elapsed, &http.Response{StatusCode: status}, nil)
+		return slot.state.interval
+	}
+
+	slot := newSlot(config)
+	observe(slot, http.StatusOK, 10*time.Millisecond)
+	if interval := observe(slot, http.StatusServiceUnavailable, 10*time.Millisecond); interval < minThrottleStep {
+		t.Fatalf("Expected slowdown after 5xx, got interval %v", interval)
+	}
+
+	// После восстановления интервал возвращается к базовому
+	var interval time.Duration
+	for i := 0; i < 30; i++ {
+		interval = observe(slot, http.StatusOK, 10*time.Millisecond)
+	}
+	if interval != 0 {
+		t.Errorf("Expected interval to recover to 0, got %v", interval)
+	}
+
+	// Рост времени ответа тоже замедляет запросы
+	for i := 0; i < 3; i++ {
+		interval = observe(slot, http.StatusOK, 2*time.Second)
+	}
+	if interval == 0 {
+		t.Error("Expected slowdown after response time increase")
+	}
+
+	// Серия ошибок увеличивает интервал один раз, а не на каждом ответе
+	throttled := *config
+	throttled.RequestDelay = 200 * time.Millisecond
+	slot = newSlot(&throttled)
+	for i := 0; i < 5; i++ {
+		interval = observe(slot, http.StatusServiceUnavailable, 10*time.Millisecond)
+	}
+	if interval != 400*time.Millisecond {
+		t.Errorf("Expected interval 400ms after a series of 5xx, got %v", interval)
+	}
+
+	// Новый эпизод перегрузки после восстановления снова увеличивает интервал
+	for i := 0; i < 30; i++ {
+		interval = observe(slot, http.StatusOK, 10*time.Millisecond)
+	}
+	if interval != throttled.RequestDelay {
+		t.Fatalf("Expected interval to recover to %v, got %v", throttled.RequestDelay, interval)
+	}
+	if interval = observe(slot, http.StatusServiceUnavailable, 10*time.Millisecond); interval != 400*time.Millisecond {
+		t.Errorf("Expected interval 400ms after new overload, got %v", interval)
+	}
+
+	config.DisableAdaptiveThrottling = true
+	slot = newSlot(config)
+	if interval := observe(slot, http.StatusServiceUnavailable, time.Second); interval != 0 {
+		t.Errorf("Expected no slowdown with DisableAdaptiveThrottling, got %v", interval)
+	}
+}
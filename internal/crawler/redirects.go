@@ -0,0 +1,212 @@
+package crawler
+
+import (
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// defaultMaxRedirects — максимальное количество переходов по редиректам по умолчанию
+const defaultMaxRedirects = 10
+
+// RedirectType определяет способ перенаправления
+type RedirectType string
+
+const (
+	// Redirect301 — постоянный редирект (301 Moved Permanently)
+	Redirect301 RedirectType = "301"
+	// Redirect302 — временный редирект (302 Found)
+	Redirect302 RedirectType = "302"
+	// Redirect303 — редирект на другой ресурс (303 See Other)
+	Redirect303 RedirectType = "303"
+	// Redirect307 — временный редирект с сохранением метода (307 Temporary Redirect)
+	Redirect307 RedirectType = "307"
+	// Redirect308 — постоянный редирект с сохранением метода (308 Permanent Redirect)
+	Redirect308 RedirectType = "308"
+	// RedirectMetaRefresh — редирект через <meta http-equiv="refresh">
+	RedirectMetaRefresh RedirectType = "meta-refresh"
+	// RedirectJavaScript — редирект через присваивание location в скрипте.
+	// Такие редиректы записываются в CrawlerResult.JavaScriptRedirect и не выполняются.
+	RedirectJavaScript RedirectType = "javascript"
+)
+
+// RedirectHop представляет один переход в цепочке редиректов
+type RedirectHop struct {
+	// URL — адрес, вернувший редирект
+	URL string
+	// StatusCode — HTTP-статус ответа (200 для meta refresh и JavaScript)
+	StatusCode int
+	// Location — абсолютный URL, на который ведет редирект
+	Location string
+	Type     RedirectType
+}
+
+// jsLocationPattern находит в начале инструкции присваивание location
+// и вызовы location.replace/assign со строковым URL
+var jsLocationPattern = regexp.MustCompile(`^(?:(?:window|document|self|top)\.)?location(?:(?:\.href)?\s*=\s*|\.(?:replace|assign)\(\s*)["']([^"']+)["']`)
+
+// httpRedirectType возвращает тип редиректа для HTTP-статуса.
+// Возвращает false, если статус не является редиректом.
+func httpRedirectType(statusCode int) (RedirectType, bool) {
+	switch statusCode {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther,
+		http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return RedirectType(strconv.Itoa(statusCode)), true
+	}
+	return "", false
+}
+
+// clientRedirect находит в документе редирект через meta refresh или JavaScript.
+// Meta refresh имеет приоритет над скриптами.
+func clientRedirect(doc *html.Node) (string, RedirectType) {
+	var refresh, script string
+
+	var f func(*html.Node)
+	f = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			switch n.Data {
+			case "meta":
+				if refresh == "" && strings.EqualFold(attrValue(n, "http-equiv"), "refresh") {
+					refresh = parseRefresh(attrValue(n, "content"))
+				}
+			case "script":
+				if script == "" && attrValue(n, "src") == "" && n.FirstChild != nil {
+					script = scriptLocation(n.FirstChild.Data)
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			f(c)
+		}
+	}
+	f(doc)
+
+	switch {
+	case refresh != "":
+		return refresh, RedirectMetaRefresh
+	case script != "":
+		return script, RedirectJavaScript
+	}
+	return "", ""
+}
+
+// scriptLocation возвращает URL первого безусловного перехода на верхнем уровне скрипта.
+// Присваивания location внутри функций, обработчиков и условий не выполняются
+// при загрузке страницы и редиректом не считаются.
+func scriptLocation(script string) string {
+	for _, statement := range topLevelStatements(script) {
+		if match := jsLocationPattern.FindStringSubmatch(strings.TrimSpace(statement)); match != nil {
+			return match[1]
+		}
+	}
+	return ""
+}
+
+// topLevelStatements разбивает скрипт на инструкции верхнего уровня.
+// Содержимое блоков {...} отбрасывается, строки и комментарии пропускаются.
+// Перевод строки после ")" не завершает инструкцию: if (...) и for (...) относятся к следующей строке.
+func topLevelStatements(script string) []string {
+	var statements []string
+	depth, start := 0, 0
+	var last byte
+	for i := 0; i < len(script); i++ {
+		c := script[i]
+		switch {
+		case c == '"' || c == '\'' || c == '`':
+			for i++; i < len(script) && script[i] != c; i++ {
+				if script[i] == '\\' {
+					i++
+				}
+			}
+		case strings.HasPrefix(script[i:], "//") || strings.HasPrefix(script[i:], "/*"):
+			var end int
+			if c = script[i+1]; c == '/' {
+				// Перевод строки после комментария обрабатывается как обычно
+				if end = strings.IndexByte(script[i:], '\n'); end < 0 {
+					end = len(script) - i
+				}
+			} else if end = strings.Index(script[i+2:], "*/"); end < 0 {
+				end = len(script) - i
+			} else {
+				end += 4
+			}
+			// Комментарий в начале инструкции не входит в ее текст
+			if strings.TrimSpace(script[start:i]) == "" {
+				start = i + end
+			}
+			i += end - 1
+			continue
+		case c == '(' || c == '[' || c == '{':
+			depth++
+		case c == ')' || c == ']' || c == '}':
+			if depth > 0 {
+				depth--
+			}
+			if c == '}' && depth == 0 {
+				start = i + 1
+			}
+		case depth == 0 && (c == ';' || (c == '\n' && last != ')')):
+			statements = append(statements, script[start:i])
+			start = i + 1
+		}
+		if c != ' ' && c != '\t' && c != '\r' && c != '\n' {
+			last = c
+		}
+	}
+	if start < len(script) {
+		statements = append(statements, script[start:])
+	}
+	return statements
+}
+
+// parseRefresh извлекает URL из значения meta refresh ("0; url=/new")
+func parseRefresh(content string) string {
+	_, target, ok := strings.Cut(content, ";")
+	if !ok {
+		if _, target, ok = strings.Cut(content, ","); !ok {
+			return ""
+		}
+	}
+	target = strings.TrimSpace(target)
+	if len(target) >= 3 && strings.EqualFold(target[:3], "url") {
+		if rest := strings.TrimSpace(target[3:]); strings.HasPrefix(rest, "=") {
+			target = strings.TrimSpace(rest[1:])
+		}
+	}
+	return strings.Trim(target, `"' `)
+}
+
+// resolveLocation разрешает адрес редиректа относительно текущего URL.
+// Возвращает false для адресов, по которым нельзя перейти по HTTP.
+func resolveLocation(current, location string) (string, bool) {
+	base, err := url.Parse(current)
+	if err != nil {
+		return "", false
+	}
+	ref, err := url.Parse(strings.TrimSpace(location))
+	if err != nil {
+		return "", false
+	}
+
+	target := base.ResolveReference(ref)
+	if (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return "", false
+	}
+	target.Fragment = ""
+	target.RawFragment = ""
+	return target.String(), true
+}
+
+// attrValue возвращает значение атрибута элемента
+func attrValue(n *html.Node, key string) string {
+	for _, attr := range n.Attr {
+		if attr.Key == key {
+			return attr.Val
+		}
+	}
+	return ""
+}
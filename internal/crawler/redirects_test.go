@@ -0,0 +1,49 @@
+package crawler
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+func TestClientRedirect(t *testing.T) {
+	tests := []struct {
+		name         string
+		page         string
+		expectedURL  string
+		expectedType RedirectType
+	}{
+		{"meta refresh", `<meta http-equiv="refresh" content="0; url=/new">`, "/new", RedirectMetaRefresh},
+		{"meta refresh quoted uppercase", `<meta http-equiv="Refresh" content="5;URL='/new'">`, "/new", RedirectMetaRefresh},
+		{"meta refresh without url", `<meta http-equiv="refresh" content="30">`, "", ""},
+		{"location href", `<script>window.location.href = "/js";</script>`, "/js", RedirectJavaScript},
+		{"location assign", `<script>location = '/js'</script>`, "/js", RedirectJavaScript},
+		{"location replace", `<script>document.location.replace("/js")</script>`, "/js", RedirectJavaScript},
+		{"comparison", `<script>if (location.href == "/js") {}</script>`, "", ""},
+		{"other object", `<script>geo.location = "/js"</script>`, "", ""},
+		{"external script", `<script src="/app.js">location = "/js"</script>`, "", ""},
+		{"location without semicolons", "<script>var lang = 'ru'\nlocation.href = '/js'</script>", "/js", RedirectJavaScript},
+		{"location after comment", "<script>// переход\n/* на /old */ location = '/js';</script>", "/js", RedirectJavaScript},
+		{"location after function", `<script>function f() { return "}"; } top.location.href = "/js";</script>`, "/js", RedirectJavaScript},
+		{"inside function", `<script>function goCart(){ window.location.href = "/cart"; }</script>`, "", ""},
+		{"inside event handler", `<script>button.onclick = function() { location = "/cart"; };</script>`, "", ""},
+		{"inside arrow function", `<script>btn.addEventListener("click", () => location.assign("/cart"))</script>`, "", ""},
+		{"conditional", "<script>if (!user)\n  location.href = '/login';</script>", "", ""},
+		{"in string", `<script>var s = 'location = "/js"';</script>`, "", ""},
+		{"meta wins", `<meta http-equiv="refresh" content="0;url=/meta"><script>location = "/js"</script>`, "/meta", RedirectMetaRefresh},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := html.Parse(strings.NewReader(tt.page))
+			if err != nil {
+				t.Fatalf("html.Parse failed: %v", err)
+			}
+			target, redirectType := clientRedirect(doc)
+			if target != tt.expectedURL || redirectType != tt.expectedType {
+				t.Errorf("clientRedirect() = %q, %q; want %q, %q", target, redirectType, tt.expectedURL, tt.expectedType)
+			}
+		})
+	}
+}
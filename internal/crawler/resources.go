@@ -0,0 +1,84 @@
+package crawler
+
+import (
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// defaultMaxBodySize — максимальный размер тела ответа по умолчанию (10 МБ)
+const defaultMaxBodySize = 10 << 20
+
+// sniffLen — количество байт, по которым определяется MIME-тип содержимого
+const sniffLen = 512
+
+// ResourceFetchMode определяет, как загружаются ресурсы, не являющиеся HTML
+type ResourceFetchMode string
+
+const (
+	// ResourceFetchPartial — GET-запрос, из тела читается только начало для определения типа.
+	// Используется по умолчанию.
+	ResourceFetchPartial ResourceFetchMode = "partial"
+	// ResourceFetchHead — HEAD-запрос для URL с расширением файла (PDF, изображения и т.п.).
+	// Если сервер не поддерживает HEAD, выполняется частичный GET.
+	ResourceFetchHead ResourceFetchMode = "head"
+)
+
+// resourceExtensions содержит расширения файлов, которые не являются HTML-страницами
+var resourceExtensions = map[string]bool{
+	".pdf": true, ".doc": true, ".docx": true, ".xls": true, ".xlsx": true, ".ppt": true, ".pptx": true,
+	".jpg": true, ".jpeg": true, ".png": true, ".gif": true, ".webp": true, ".avif": true, ".svg": true, ".ico": true, ".bmp": true,
+	".mp3": true, ".mp4": true, ".avi": true, ".mov": true, ".webm": true, ".wav": true,
+	".zip": true, ".rar": true, ".7z": true, ".gz": true, ".tar": true,
+	".css": true, ".js": true, ".json": true, ".xml": true, ".txt": true, ".csv": true,
+	".woff": true, ".woff2": true, ".ttf": true, ".eot": true,
+	".exe": true, ".dmg": true, ".apk": true, ".iso": true,
+}
+
+// isResourceURL проверяет, указывает ли URL на файл, не являющийся HTML-страницей
+func isResourceURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	return resourceExtensions[strings.ToLower(path.Ext(u.Path))]
+}
+
+// detectMIMEType определяет MIME-тип ответа по заголовку Content-Type.
+// Если заголовок не задан или не несет информации о типе, тип определяется по содержимому.
+// Если заголовок указывает HTML, а по содержимому это явно не текст (PDF, изображение, архив),
+// используется тип по содержимому: такие ответы не разбираются как страницы.
+func detectMIMEType(contentType string, prefix []byte) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err == nil && mediaType != "application/octet-stream" && mediaType != "unknown/unknown" {
+		if isHTMLType(mediaType) && len(prefix) > 0 {
+			if sniffed := sniffMIMEType(prefix); isBinaryType(sniffed) {
+				return sniffed
+			}
+		}
+		return mediaType
+	}
+	if len(prefix) == 0 {
+		return mediaType
+	}
+	return sniffMIMEType(prefix)
+}
+
+// sniffMIMEType определяет MIME-тип по первым байтам содержимого
+func sniffMIMEType(prefix []byte) string {
+	mediaType, _, _ := mime.ParseMediaType(http.DetectContentType(prefix))
+	return mediaType
+}
+
+// isBinaryType проверяет, что тип, определенный по содержимому, явно не является текстом.
+// application/octet-stream означает только то, что тип определить не удалось.
+func isBinaryType(mediaType string) bool {
+	return mediaType != "" && mediaType != "application/octet-stream" && !strings.HasPrefix(mediaType, "text/")
+}
+
+// isHTMLType проверяет, является ли MIME-тип HTML-документом
+func isHTMLType(mediaType string) bool {
+	return mediaType == "text/html" || mediaType == "application/xhtml+xml"
+}
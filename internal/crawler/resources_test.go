@@ -0,0 +1,50 @@
+package crawler
+
+import "testing"
+
+func TestDetectMIMEType(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		prefix      string
+		expected    string
+	}{
+		{"html header", "text/html; charset=utf-8", "", "text/html"},
+		{"xhtml header", "application/xhtml+xml", "", "application/xhtml+xml"},
+		{"pdf header", "application/pdf", "<html>", "application/pdf"},
+		{"missing header sniffed html", "", "<!DOCTYPE html><html><body>", "text/html"},
+		{"octet stream sniffed html", "application/octet-stream", "<html><head>", "text/html"},
+		{"octet stream sniffed pdf", "application/octet-stream", "%PDF-1.7", "application/pdf"},
+		{"missing header sniffed png", "", "\x89PNG\r\n\x1a\n", "image/png"},
+		{"missing header without body", "", "", ""},
+		{"html header with html body", "text/html", "<!DOCTYPE html><html>", "text/html"},
+		{"html header with plain text body", "text/html", "Hello, world", "text/html"},
+		{"html header with pdf body", "text/html; charset=utf-8", "%PDF-1.7", "application/pdf"},
+		{"html header with png body", "text/html", "\x89PNG\r\n\x1a\n", "image/png"},
+		{"html header with zip body", "text/html", "PK\x03\x04", "application/zip"},
+		{"html header with unknown binary body", "text/html", "\x00\x01\x02\x03", "text/html"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detectMIMEType(tt.contentType, []byte(tt.prefix)); got != tt.expected {
+				t.Errorf("detectMIMEType(%q, %q) = %q; want %q", tt.contentType, tt.prefix, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestIsResourceURL(t *testing.T) {
+	tests := map[string]bool{
+		"https://site.com/files/report.PDF": true,
+		"https://site.com/img/logo.png?v=2": true,
+		"https://site.com/about":            false,
+		"https://site.com/index.html":       false,
+		"https://site.com/page.php":         false,
+	}
+	for rawURL, expected := range tests {
+		if got := isResourceURL(rawURL); got != expected {
+			t.Errorf("isResourceURL(%s) = %v; want %v", rawURL, got, expected)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package crawler
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"strings"
+	"time"
+)
+
+// acceptEncoding — поддерживаемые схемы сжатия. Заголовок выставляется явно,
+// чтобы транспорт не распаковывал ответ сам и можно было измерить сжатый размер.
+const acceptEncoding = "gzip, deflate"
+
+// responseTiming собирает сведения о соединении и времени ответа через httptrace
+type responseTiming struct {
+	start    time.Time
+	ttfb     time.Duration
+	serverIP string
+}
+
+// withTrace добавляет трассировку запроса в контекст
+func (t *responseTiming) withTrace(req *http.Request) *http.Request {
+	trace := &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			if addr := info.Conn.RemoteAddr(); addr != nil {
+				if host, _, err := net.SplitHostPort(addr.String()); err == nil {
+					t.serverIP = host
+				}
+			}
+		},
+		GotFirstResponseByte: func() {
+			t.ttfb = time.Since(t.start)
+		},
+	}
+	t.start = time.Now()
+	return req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+}
+
+// countingReader подсчитывает количество прочитанных байт
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// bodyReader читает тело ответа, распаковывая его согласно Content-Encoding,
+// и подсчитывает размер тела в том виде, в котором оно передано по сети
+type bodyReader struct {
+	counter *countingReader
+	reader  *bufio.Reader
+	closers []io.Closer
+}
+
+// newBodyReader создает bodyReader для тела ответа
+func newBodyReader(resp *http.Response) (*bodyReader, error) {
+	b := &bodyReader{counter: &countingReader{r: resp.Body}}
+
+	var reader io.Reader = b.counter
+	switch strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding"))) {
+	case "gzip", "x-gzip":
+		gz, err := gzip.NewReader(b.counter)
+		switch {
+		case err == io.EOF:
+			// Пустое тело
+		case err != nil:
+			return nil, err
+		default:
+			b.closers = append(b.closers, gz)
+			reader = gz
+		}
+	case "deflate":
+		// Часть серверов отдает deflate без zlib-обертки
+		br := bufio.NewReader(b.counter)
+		if header, err := br.Peek(2); err == nil && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+			zr, err := zlib.NewReader(br)
+			if err != nil {
+				return nil, err
+			}
+			b.closers = append(b.closers, zr)
+			reader = zr
+		} else {
+			fr := flate.NewReader(br)
+			b.closers = append(b.closers, fr)
+			reader = fr
+		}
+	}
+
+	b.reader = bufio.NewReaderSize(reader, sniffLen)
+	return b, nil
+}
+
+// peek возвращает первые n байт распакованного тела без их извлечения
+func (b *bodyReader) peek(n int) []byte {
+	prefix, _ := b.reader.Peek(n)
+	return prefix
+}
+
+// readAll читает распакованное тело, но не больше limit байт.
+// Возвращает true, если тело длиннее limit и было обрезано.
+func (b *bodyReader) readAll(limit int64) ([]byte, bool, error) {
+	body, err := io.ReadAll(io.LimitReader(b.reader, limit+1))
+	if err != nil {
+		return nil, false, err
+	}
+	if int64(len(body)) > limit {
+		return body[:limit], true, nil
+	}
+
+	// Дочитываем остаток, чтобы учесть весь переданный объем
+	io.Copy(io.Discard, b.counter)
+	return body, false, nil
+}
+
+// compressedSize возвращает количество байт, прочитанных из сети
+func (b *bodyReader) compressedSize() int64 {
+	return b.counter.n
+}
+
+// Close освобождает ресурсы распаковки. Тело ответа закрывает вызывающий.
+func (b *bodyReader) Close() {
+	for _, closer := range b.closers {
+		closer.Close()
+	}
+}
+
+// readResponse читает тело ответа и определяет его MIME-тип.
+// Ресурсы, не являющиеся HTML, не загружаются целиком: читается только префикс
+// для определения типа, размер берется из Content-Length.
+// Возвращает тело HTML-документа или nil.
+func readResponse(result *CrawlerResult, resp *http.Response, maxBodySize int64) ([]byte, error) {
+	if resp.Request.Method == http.MethodHead {
+		result.MIMEType = detectMIMEType(result.ContentType, nil)
+		return nil, nil
+	}
+
+	reader, err := newBodyReader(resp)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	result.MIMEType = detectMIMEType(result.ContentType, reader.peek(sniffLen))
+	if !isHTMLType(result.MIMEType) {
+		return nil, nil
+	}
+
+	body, truncated, err := reader.readAll(maxBodySize)
+	if err != nil {
+		return nil, err
+	}
+	result.CompressedSize = reader.compressedSize()
+	result.UncompressedSize = int64(len(body))
+	result.BodyTruncated = truncated
+	return body, nil
+}
+
+// applyResponse заполняет метаданные HTTP-ответа в результате
+func applyResponse(result *CrawlerResult, resp *http.Response, timing *responseTiming) {
+	result.StatusCode = resp.StatusCode
+	result.FinalURL = resp.Request.URL.String()
+	result.Headers = resp.Header.Clone()
+	result.ContentType = resp.Header.Get("Content-Type")
+	result.ContentLength = resp.ContentLength
+	result.ContentEncoding = resp.Header.Get("Content-Encoding")
+	result.XRobotsTag = resp.Header.Values("X-Robots-Tag")
+	result.Robots = RobotsDirectives{}
+	for _, value := range result.XRobotsTag {
+		result.Robots.addXRobotsTag(value)
+	}
+	result.Hreflang = headerHreflang(resp)
+	result.Protocol = resp.Proto
+	result.ServerIP = timing.serverIP
+	result.TTFB = timing.ttfb
+}
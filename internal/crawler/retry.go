@@ -0,0 +1,170 @@
+package crawler
+
+import (
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// defaultRetryBudget — количество повторов запросов к одному хосту по умолчанию
+const defaultRetryBudget = 100
+
+// DefaultRetryStatuses содержит HTTP-статусы, при которых запрос повторяется по умолчанию
+var DefaultRetryStatuses = []int{
+	http.StatusTooManyRequests,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// RetryPolicy определяет, нужно ли повторить запрос и сколько ждать перед повтором
+type RetryPolicy interface {
+	// ShouldRetry вызывается после попытки attempt (1 — первая попытка).
+	// resp равен nil, если запрос завершился сетевой ошибкой err.
+	// Возвращает задержку перед следующей попыткой и false, если повторять не нужно.
+	ShouldRetry(attempt int, resp *http.Response, err error) (time.Duration, bool)
+}
+
+// ExponentialBackoff реализует RetryPolicy с экспоненциальной задержкой и случайным разбросом.
+// Заголовок Retry-After имеет приоритет над вычисленной задержкой.
+// Из сетевых ошибок повторяются только временные (см. retryableError).
+type ExponentialBackoff struct {
+	// MaxAttempts — общее количество попыток, включая первую
+	MaxAttempts int
+	// BaseDelay — задержка перед первым повтором, далее удваивается
+	BaseDelay time.Duration
+	// MaxDelay ограничивает задержку. Если Retry-After требует ждать дольше, запрос не повторяется.
+	MaxDelay time.Duration
+	// Jitter — доля задержки (от 0 до 1), заменяемая случайной величиной
+	Jitter float64
+	// RetryStatuses — статусы, при которых запрос повторяется (по умолчанию DefaultRetryStatuses)
+	RetryStatuses []int
+}
+
+// NewExponentialBackoff создает политику повторов с параметрами по умолчанию
+func NewExponentialBackoff(maxAttempts int) *ExponentialBackoff {
+	return &ExponentialBackoff{
+		MaxAttempts: maxAttempts,
+		BaseDelay:   500 * time.Millisecond,
+		MaxDelay:    30 * time.Second,
+		Jitter:      0.5,
+	}
+}
+
+// ShouldRetry реализует метод интерфейса RetryPolicy
+func (b *ExponentialBackoff) ShouldRetry(attempt int, resp *http.Response, err error) (time.Duration, bool) {
+	if attempt >= b.MaxAttempts {
+		return 0, false
+	}
+	if err != nil && !retryableError(err) {
+		return 0, false
+	}
+	if err == nil && !b.retryStatus(resp.StatusCode) {
+		return 0, false
+	}
+
+	if resp != nil {
+		if delay, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+			if b.MaxDelay > 0 && delay > b.MaxDelay {
+				return 0, false
+			}
+			return delay, true
+		}
+	}
+
+	delay := b.BaseDelay
+	for i := 1; i < attempt && (b.MaxDelay <= 0 || delay < b.MaxDelay); i++ {
+		delay *= 2
+	}
+	if b.MaxDelay > 0 && delay > b.MaxDelay {
+		delay = b.MaxDelay
+	}
+	if b.Jitter > 0 && delay > 0 {
+		spread := time.Duration(float64(delay) * min(b.Jitter, 1))
+		delay = delay - spread + rand.N(spread+1)
+	}
+	return delay, true
+}
+
+// retryStatus проверяет, нужно ли повторять запрос при статусе
+func (b *ExponentialBackoff) retryStatus(statusCode int) bool {
+	statuses := b.RetryStatuses
+	if statuses == nil {
+		statuses = DefaultRetryStatuses
+	}
+	for _, status := range statuses {
+		if status == statusCode {
+			return true
+		}
+	}
+	return false
+}
+
+// retryableError проверяет, что сетевая ошибка временная: таймаут, сброс или отказ
+// в соединении, обрыв ответа. Ошибки сертификата, несуществующий домен и невалидный URL
+// при повторе не исчезнут, поэтому не повторяются.
+func retryableError(err error) bool {
+	if errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// parseRetryAfter разбирает заголовок Retry-After в секундах или в формате HTTP-даты
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(date.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+// retryBudget ограничивает общее количество повторов запросов к одному хосту,
+// чтобы недоступный хост не вызывал лавину повторов
+type retryBudget struct {
+	mu    sync.Mutex
+	limit int
+	used  map[string]int
+}
+
+// newRetryBudget создает бюджет повторов. Отрицательный limit снимает ограничение.
+func newRetryBudget(limit int) *retryBudget {
+	if limit == 0 {
+		limit = defaultRetryBudget
+	}
+	return &retryBudget{
+		limit: limit,
+		used:  make(map[string]int),
+	}
+}
+
+// take расходует один повтор для хоста. Возвращает false, если бюджет исчерпан.
+func (b *retryBudget) take(host string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.limit >= 0 && b.used[host] >= b.limit {
+		return false
+	}
+	b.used[host]++
+	return true
+}
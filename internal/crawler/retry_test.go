@@ -0,0 +1,138 @@
+package crawler
+
+import (
+	"crypto/x509"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestExponentialBackoff_ShouldRetry(t *testing.T) {
+	policy := &ExponentialBackoff{
+		MaxAttempts: 4,
+		BaseDelay:   100 * time.Millisecond,
+		MaxDelay:    time.Second,
+	}
+
+	response := func(status int, retryAfter string) *http.Response {
+		resp := &http.Response{StatusCode: status, Header: make(http.Header)}
+		if retryAfter != "" {
+			resp.Header.Set("Retry-After", retryAfter)
+		}
+		return resp
+	}
+
+	// transportError оборачивает ошибку так же, как http.Client
+	transportError := func(err error) error {
+		return &url.Error{Op: "Get", URL: "https://example.com/", Err: err}
+	}
+	connError := func(errno syscall.Errno) error {
+		return transportError(&net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", errno)})
+	}
+
+	tests := []struct {
+		name          string
+		attempt       int
+		resp          *http.Response
+		err           error
+		expectedDelay time.Duration
+		expectedRetry bool
+	}{
+		{"connection reset", 1, nil, connError(syscall.ECONNRESET), 100 * time.Millisecond, true},
+		{"connection refused", 2, nil, connError(syscall.ECONNREFUSED), 200 * time.Millisecond, true},
+		{"timeout", 1, nil, transportError(os.ErrDeadlineExceeded), 100 * time.Millisecond, true},
+		{"unexpected eof", 1, nil, transportError(io.ErrUnexpectedEOF), 100 * time.Millisecond, true},
+		{"certificate error", 1, nil, transportError(x509.UnknownAuthorityError{}), 0, false},
+		{"no such host", 1, nil, transportError(&net.DNSError{Err: "no such host", Name: "example.invalid", IsNotFound: true}), 0, false},
+		{"invalid url", 1, nil, &url.Error{Op: "parse", URL: "http://[::1", Err: errors.New("missing ']' in host")}, 0, false},
+		{"unknown error", 1, nil, errors.New("unsupported protocol scheme"), 0, false},
+		{"service unavailable", 2, response(http.StatusServiceUnavailable, ""), nil, 200 * time.Millisecond, true},
+		{"too many requests", 3, response(http.StatusTooManyRequests, ""), nil, 400 * time.Millisecond, true},
+		{"ok", 1, response(http.StatusOK, ""), nil, 0, false},
+		{"not found", 1, response(http.StatusNotFound, ""), nil, 0, false},
+		{"internal server error", 1, response(http.StatusInternalServerError, ""), nil, 0, false},
+		{"attempts exhausted", 4, response(http.StatusBadGateway, ""), nil, 0, false},
+		{"retry after seconds", 1, response(http.StatusTooManyRequests, "1"), nil, time.Second, true},
+		{"retry after too long", 1, response(http.StatusServiceUnavailable, "120"), nil, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			delay, retry := policy.ShouldRetry(tt.attempt, tt.resp, tt.err)
+			if retry != tt.expectedRetry || delay != tt.expectedDelay {
+				t.Errorf("ShouldRetry(%d) = %v, %v; want %v, %v", tt.attempt, delay, retry, tt.expectedDelay, tt.expectedRetry)
+			}
+		})
+	}
+}
+
+func TestExponentialBackoff_Jitter(t *testing.T) {
+	policy := &ExponentialBackoff{
+		MaxAttempts: 10,
+		BaseDelay:   time.Second,
+		MaxDelay:    4 * time.Second,
+		Jitter:      0.5,
+	}
+
+	for attempt := 1; attempt < 10; attempt++ {
+		delay, retry := policy.ShouldRetry(attempt, nil, os.ErrDeadlineExceeded)
+		if !retry {
+			t.Fatalf("Expected retry on attempt %d", attempt)
+		}
+		upper := min(time.Second<<(attempt-1), 4*time.Second)
+		if delay < upper/2 || delay > upper {
+			t.Errorf("Attempt %d: delay %v outside [%v, %v]", attempt, delay, upper/2, upper)
+		}
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value    string
+		expected time.Duration
+		ok       bool
+	}{
+		{"30", 30 * time.Second, true},
+		{" 0 ", 0, true},
+		{"-5", 0, false},
+		{"Mon, 01 Jan 2024 12:00:10 GMT", 10 * time.Second, true},
+		{"Mon, 01 Jan 2024 11:00:00 GMT", 0, true},
+		{"soon", 0, false},
+		{"", 0, false},
+	}
+
+	for _, tt := range tests {
+		delay, ok := parseRetryAfter(tt.value, now)
+		if ok != tt.ok || delay != tt.expected {
+			t.Errorf("parseRetryAfter(%q) = %v, %v; want %v, %v", tt.value, delay, ok, tt.expected, tt.ok)
+		}
+	}
+}
+
+func TestRetryBudget_Take(t *testing.T) {
+	budget := newRetryBudget(2)
+	if !budget.take("a.com") || !budget.take("a.com") {
+		t.Fatal("Expected two retries within budget")
+	}
+	if budget.take("a.com") {
+		t.Error("Expected budget for a.com to be exhausted")
+	}
+	if !budget.take("b.com") {
+		t.Error("Expected separate budget for b.com")
+	}
+
+	unlimited := newRetryBudget(-1)
+	for i := 0; i < 1000; i++ {
+		if !unlimited.take("a.com") {
+			t.Fatal("Expected unlimited budget")
+		}
+	}
+}
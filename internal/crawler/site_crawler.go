@@ -2,25 +2,55 @@ package crawler
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"hash/fnv"
 	"log"
+	"net/http"
 	"net/url"
-	"path"
-	"strings"
+	"sort"
 	"sync"
 	"time"
+
+	"rank-vision/internal/robots"
+	"rank-vision/internal/sitemap"
 )
 
+// defaultWorkers — количество параллельных воркеров по умолчанию
+const defaultWorkers = 5
+
 // SiteCrawler представляет краулер для всего сайта
 type SiteCrawler struct {
-	baseURL     *url.URL
-	config      *Config
-	results     map[string]*CrawlerResult
-	queue       chan string
-	visited     map[string]bool
-	visitedLock sync.RWMutex
+	baseURL    *url.URL
+	start      string
+	normalizer *URLNormalizer
+	config     *Config
+	results    map[string]*CrawlerResult
+	client     *http.Client
+	robots     *robots.Cache
+	// crawler загружает страницы. Один экземпляр на весь краулинг сохраняет
+	// keep-alive соединения, ограничитель запросов и бюджет повторов.
+	crawler     *HTTPCrawler
+	sitemapURLs []sitemap.URL
+	// sitemapLocs хранит адреса из sitemap в исходном написании по нормализованному URL
+	sitemapLocs map[string]string
 	maxPages    int
 	maxDepth    int
+
+	// mu защищает очередь, множество посещенных URL и счетчик pending.
+	// Воркеры ждут новых URL на cond.
+	mu       sync.Mutex
+	cond     *sync.Cond
+	frontier Frontier
+	// visited хранит 64-битные отпечатки URL вместо самих строк,
+	// чтобы множество оставалось компактным на миллионах URL
+	visited map[uint64]struct{}
+	// pending — количество URL в очереди и в обработке
+	pending int
+	// frontierErr — ошибка очереди, прервавшая краулинг
+	frontierErr error
+	// dropped — количество ссылок, отброшенных из-за MaxQueueSize
+	dropped int
 }
 
 // SiteCrawlerResult представляет результат краулинга всего сайта
@@ -32,7 +62,73 @@ type SiteCrawlerResult struct {
 	EndTime    time.Time
 	Pages      map[string]*CrawlerResult
 	Errors     map[string]error
-	Statistics SiteStatistics
+	// DroppedURLs — количество ссылок, не добавленных в очередь из-за Config.MaxQueueSize
+	DroppedURLs int
+	// BlockedByRobots содержит URL, запрещенные robots.txt, и сработавшее правило
+	BlockedByRobots map[string]string
+	Sitemap         SitemapReport
+	Normalization   NormalizationReport
+	Redirects       RedirectReport
+	Links           LinkReport
+	LinkGraph       LinkGraphReport
+	Images          ImageReport
+	StructuredData  StructuredDataReport
+	Social          SocialReport
+	Hreflang        HreflangReport
+	Statistics      SiteStatistics
+}
+
+// RedirectReport содержит проблемные редиректы сайта
+type RedirectReport struct {
+	// Chains содержит редиректы длиннее одного перехода
+	Chains []Redirect
+	// Loops содержит зацикленные редиректы
+	Loops []Redirect
+	// ToClientErrors содержит редиректы, ведущие на страницы с ответом 4xx
+	ToClientErrors []Redirect
+	// LinkedRedirects содержит внутренние ссылки на URL с редиректом
+	LinkedRedirects []RedirectedLink
+	// JavaScript содержит страницы, безусловно переходящие на другой адрес скриптом.
+	// Краулер по таким редиректам не переходит.
+	JavaScript []Redirect
+}
+
+// Redirect представляет запрошенный URL и цепочку его редиректов
+type Redirect struct {
+	URL             string
+	FinalURL        string
+	FinalStatusCode int
+	Hops            []RedirectHop
+}
+
+// RedirectedLink представляет внутреннюю ссылку на URL с редиректом
+type RedirectedLink struct {
+	PageURL  string
+	Anchor   string
+	URL      string
+	FinalURL string
+}
+
+// NormalizationReport объясняет, какие URL были сведены к одному
+type NormalizationReport struct {
+	// Rules содержит описание примененных правил нормализации
+	Rules []string
+	// Variants содержит для нормализованного URL варианты написания, найденные в ссылках
+	Variants map[string][]string
+}
+
+// SitemapReport содержит сравнение sitemap с просканированными страницами
+type SitemapReport struct {
+	// Sitemaps содержит обработанные файлы sitemap
+	Sitemaps []string
+	// TotalURLs — количество уникальных URL во всех sitemap
+	TotalURLs int
+	// NotLinked содержит URL из sitemap, на которые нет ссылок с просканированных страниц
+	NotLinked []string
+	// MissingFromSitemap содержит просканированные страницы, отсутствующие в sitemap
+	MissingFromSitemap []string
+	// Errors содержит ошибки загрузки sitemap
+	Errors map[string]string
 }
 
 // SiteStatistics содержит статистику по сайту
@@ -40,12 +136,43 @@ type SiteStatistics struct {
 	TotalWordCount   int
 	AverageWordCount int
 	UniqueDomains    map[string]int
-	BrokenLinks      []string
-	MissingMetaDesc  []string
-	MissingTitle     []string
+	// LinkTypes содержит количество ссылок каждого типа на всех страницах
+	LinkTypes map[LinkType]int
+	// DepthDistribution содержит количество страниц на каждой глубине клика
+	DepthDistribution map[int]int
+	// StatusCodes содержит количество страниц с каждым HTTP-статусом
+	StatusCodes map[int]int
+	// ContentTypes содержит количество страниц и ресурсов каждого MIME-типа
+	ContentTypes map[string]int
+	// BrokenLinks содержит внутренние URL с ответом 4xx/5xx (в том числе после редиректов) и ссылки на них
+	BrokenLinks []BrokenLink
+	// FlakyPages содержит страницы, загруженные не с первой попытки
+	FlakyPages      []string
+	MissingMetaDesc []string
+	MissingTitle    []string
+	// Headings содержит проверки структуры заголовков h1–h6
+	Headings HeadingReport
+	// Indexability содержит анализ индексируемости и canonical
+	Indexability IndexabilityReport
+	// CharsetMismatches содержит страницы, объявленная кодировка которых противоречит фактической
+	CharsetMismatches []string
+}
+
+// BrokenLink представляет URL с ответом 4xx/5xx и страницы, ссылающиеся на него
+type BrokenLink struct {
+	URL        string
+	StatusCode int
+	Sources    []LinkSource
 }
 
-// NewSiteCrawler создает новый экземпляр SiteCrawler
+// LinkSource представляет ссылку на URL с другой страницы
+type LinkSource struct {
+	PageURL string
+	Anchor  string
+}
+
+// NewSiteCrawler создает новый экземпляр SiteCrawler.
+// Отрицательный maxDepth снимает ограничение глубины, 0 — краулинг только начального URL.
 func NewSiteCrawler(baseURL string, config *Config, maxPages, maxDepth int) (*SiteCrawler, error) {
 	parsedURL, err := url.Parse(baseURL)
 	if err != nil {
@@ -55,16 +182,44 @@ func NewSiteCrawler(baseURL string, config *Config, maxPages, maxDepth int) (*Si
 	if config == nil {
 		config = DefaultConfig()
 	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	normalizer := NewURLNormalizer(config.Normalization)
+	start, err := normalizer.Normalize(nil, baseURL)
+	if err != nil {
+		return nil, err
+	}
 
-	return &SiteCrawler{
-		baseURL:  parsedURL,
-		config:   config,
-		results:  make(map[string]*CrawlerResult),
-		queue:    make(chan string, 1000),
-		visited:  make(map[string]bool),
-		maxPages: maxPages,
-		maxDepth: maxDepth,
-	}, nil
+	// Все запросы краулинга (страницы, robots.txt, sitemap) идут через один транспорт
+	transport := config.Transport
+	if transport == nil {
+		transport = NewTransport(config)
+	}
+	client := &http.Client{
+		Transport: transport,
+		Timeout:   config.Timeout,
+	}
+	robotsCache := robots.NewCache(client, config.UserAgent)
+
+	sc := &SiteCrawler{
+		baseURL:     parsedURL,
+		start:       start,
+		normalizer:  normalizer,
+		config:      config,
+		results:     make(map[string]*CrawlerResult),
+		client:      client,
+		robots:      robotsCache,
+		crawler:     newHTTPCrawler(config, transport, robotsCache),
+		visited:     make(map[uint64]struct{}),
+		sitemapLocs: make(map[string]string),
+		maxPages:    maxPages,
+		maxDepth:    maxDepth,
+	}
+	sc.cond = sync.NewCond(&sc.mu)
+
+	return sc, nil
 }
 
 // CrawlSite запускает краулинг всего сайта
@@ -77,161 +232,612 @@ func (sc *SiteCrawler) CrawlSite(ctx context.Context) (*SiteCrawlerResult, error
 		StartTime: startTime,
 		Pages:     make(map[string]*CrawlerResult),
 		Errors:    make(map[string]error),
+
+		BlockedByRobots: make(map[string]string),
+		Normalization: NormalizationReport{
+			Rules:    sc.normalizer.Rules(),
+			Variants: make(map[string][]string),
+		},
+		Sitemap: SitemapReport{
+			Errors: make(map[string]string),
+		},
 		Statistics: SiteStatistics{
-			UniqueDomains: make(map[string]int),
+			UniqueDomains:     make(map[string]int),
+			StatusCodes:       make(map[int]int),
+			ContentTypes:      make(map[string]int),
+			LinkTypes:         make(map[LinkType]int),
+			DepthDistribution: make(map[int]int),
 		},
 	}
 
+	frontier, err := sc.newFrontier()
+	if err != nil {
+		return nil, err
+	}
+	defer frontier.Close()
+	sc.frontier = frontier
+
+	// Будим ожидающих воркеров при отмене контекста
+	stop := context.AfterFunc(ctx, func() {
+		sc.mu.Lock()
+		sc.cond.Broadcast()
+		sc.mu.Unlock()
+	})
+	defer stop()
+
+	// Пока идет добавление начальных URL, краулинг не может завершиться
+	sc.pending = 1
+
 	// Запускаем воркеры для обработки URL
 	var wg sync.WaitGroup
-	numWorkers := 5 // Количество параллельных воркеров
+	numWorkers := sc.numWorkers()
 	log.Printf("Запускаем %d воркеров", numWorkers)
 
+	collector := newResultCollector(result, sc.maxPages)
+
+	// Начальный URL обрабатывается до запуска воркеров: если он перенаправляет на другой
+	// хост того же сайта (example.com → www.example.com), дальше сканируется конечный хост
+	initialURL := sc.start
+	log.Printf("Обрабатываем начальный URL: %s", initialURL)
+	sc.markVisited(initialURL)
+	sc.processItem(ctx, FrontierItem{URL: initialURL, FetchURL: sc.baseURL.String()}, collector)
+
 	for i := 0; i < numWorkers; i++ {
 		wg.Add(1)
-		go sc.worker(ctx, &wg, result)
+		go sc.worker(ctx, &wg, collector)
 	}
 
-	// Добавляем начальный URL в очередь
-	initialURL := sc.baseURL.String()
-	log.Printf("Добавляем начальный URL в очередь: %s", initialURL)
-	sc.queue <- initialURL
-
-	// Ждем завершения всех воркеров
-	done := make(chan bool)
-	go func() {
-		wg.Wait()
-		close(done)
-	}()
-
-	// Ждем завершения или таймаута
-	select {
-	case <-done:
+	// Добавляем URL из sitemap. Они не достижимы по ссылкам от начального URL,
+	// поэтому получают максимальную глубину: ссылки с них не обходятся сверх MaxDepth.
+	if !sc.config.IgnoreSitemaps {
+		sc.discoverSitemaps(ctx, result)
+		depth := max(sc.maxDepth, 0)
+		for _, u := range sc.sitemapURLs {
+			if sc.isValidInternalURL(u.Loc) {
+				sc.enqueue(FrontierItem{URL: u.Loc, FetchURL: sc.sitemapLocs[u.Loc], Depth: depth})
+			}
+			// Языковые версии из sitemap проверяются, даже если на них нет ссылок
+			for _, alternate := range u.Alternates {
+				if link, err := sc.normalizer.Normalize(nil, alternate.Href); err == nil && sc.isValidInternalURL(link) {
+					sc.enqueue(FrontierItem{URL: link, Depth: depth})
+				}
+			}
+		}
+	}
+	sc.finishItem()
+
+	// Воркеры завершаются, когда очередь пуста и нет страниц в обработке,
+	// либо при отмене контекста
+	wg.Wait()
+	if sc.config.Transport == nil {
+		// Собственный транспорт больше не нужен, закрываем keep-alive соединения
+		sc.client.CloseIdleConnections()
+	}
+	err = ctx.Err()
+	if sc.frontierErr != nil {
+		err = sc.frontierErr
+	}
+	if err != nil {
+		log.Printf("Краулинг прерван: %v. Сохраняем частичные результаты", err)
+	} else {
 		log.Printf("Все воркеры завершили работу")
-	case <-ctx.Done():
-		log.Printf("Краулинг прерван по таймауту")
-		return result, ctx.Err()
 	}
 
 	result.EndTime = time.Now()
 	result.TotalPages = len(result.Pages)
+	result.DroppedURLs = sc.dropped
+	sc.calculateClickDepth(result)
 	sc.calculateStatistics(result)
+	sc.auditRedirects(result)
+	sc.auditLinks(result)
+	sc.analyzeLinkGraph(result)
+	sc.auditIndexability(result)
+	sc.auditHeadings(result)
+	sc.auditImages(ctx, result)
+	sc.auditStructuredData(result)
+	sc.auditSocial(ctx, result)
+	sc.auditHreflang(result)
+	sc.compareSitemap(result)
 
 	log.Printf("Краулинг завершен. Обработано страниц: %d", result.TotalPages)
-	return result, nil
+	return result, err
 }
 
-// isValidInternalURL проверяет, является ли URL внутренним
-func (sc *SiteCrawler) isValidInternalURL(link string) bool {
-	// Обрабатываем относительные URL
-	if strings.HasPrefix(link, "/") {
-		link = sc.baseURL.Scheme + "://" + sc.baseURL.Host + link
+// numWorkers возвращает количество параллельных воркеров
+func (sc *SiteCrawler) numWorkers() int {
+	if sc.config.Workers > 0 {
+		return sc.config.Workers
 	}
+	return defaultWorkers
+}
+
+// resolveLink разрешает ссылку со страницы с учетом <base href> и нормализует ее
+func (sc *SiteCrawler) resolveLink(page *CrawlerResult, link string) (string, error) {
+	return sc.normalizer.Normalize(documentBase(page), link)
+}
 
+// isValidInternalURL проверяет, является ли абсолютный URL внутренней HTML-страницей сайта
+func (sc *SiteCrawler) isValidInternalURL(link string) bool {
 	parsedURL, err := url.Parse(link)
-	if err != nil {
+	if err != nil || !parsedURL.IsAbs() {
 		return false
 	}
-
-	// Если URL относительный, добавляем базовый домен
-	if parsedURL.Host == "" {
-		parsedURL.Host = sc.baseURL.Host
-		parsedURL.Scheme = sc.baseURL.Scheme
+	if parsedURL.Scheme != "http" && parsedURL.Scheme != "https" {
+		return false
 	}
 
-	// Проверяем, что URL принадлежит тому же домену
-	if parsedURL.Host != sc.baseURL.Host {
+	// Проверяем, что URL принадлежит тому же хосту
+	if classifyHost(sc.baseURL, parsedURL) != LinkInternal {
 		return false
 	}
 
-	// Проверяем, что это не файл
-	ext := strings.ToLower(path.Ext(parsedURL.Path))
-	if ext != "" && ext != ".html" && ext != ".htm" && ext != "/" {
+	// Проверяем, что это не файл. Страницы с расширениями .php, .aspx, .jsp и т.п. обходятся.
+	return !isResourceURL(link)
+}
+
+// isInternalResource проверяет, является ли абсолютный URL внутренним файлом (PDF, изображение и т.п.)
+func (sc *SiteCrawler) isInternalResource(link string) bool {
+	parsedURL, err := url.Parse(link)
+	if err != nil || !parsedURL.IsAbs() {
 		return false
 	}
-
-	return true
+	return classifyHost(sc.baseURL, parsedURL) == LinkInternal && isResourceURL(link)
 }
 
 // worker обрабатывает URL из очереди
-func (sc *SiteCrawler) worker(ctx context.Context, wg *sync.WaitGroup, result *SiteCrawlerResult) {
+func (sc *SiteCrawler) worker(ctx context.Context, wg *sync.WaitGroup, collector *resultCollector) {
 	defer wg.Done()
 
 	for {
-		select {
-		case <-ctx.Done():
+		item, ok := sc.next(ctx)
+		if !ok {
 			return
-		case currentURL, ok := <-sc.queue:
-			if !ok {
-				return
-			}
+		}
+		sc.processItem(ctx, item, collector)
+		sc.finishItem()
+	}
+}
 
-			log.Printf("Обработка URL: %s", currentURL)
+// next извлекает следующий URL из очереди, ожидая его появления.
+// Возвращает false, когда очередь пуста и других URL в обработке нет,
+// а также при отмене контекста или ошибке очереди.
+func (sc *SiteCrawler) next(ctx context.Context) (FrontierItem, bool) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
 
-			// Проверяем, не посещали ли мы уже этот URL
-			sc.visitedLock.RLock()
-			visited := sc.visited[currentURL]
-			sc.visitedLock.RUnlock()
-			if visited {
-				log.Printf("URL уже был посещен: %s", currentURL)
-				continue
-			}
+	for {
+		if ctx.Err() != nil || sc.frontierErr != nil {
+			return FrontierItem{}, false
+		}
 
-			// Проверяем, не превысили ли мы лимит страниц
-			if result.TotalPages >= sc.maxPages {
-				log.Printf("Достигнут лимит страниц (%d)", sc.maxPages)
-				return
-			}
+		item, ok, err := sc.frontier.Pop()
+		if err != nil {
+			log.Printf("Ошибка чтения очереди: %v", err)
+			sc.frontierErr = fmt.Errorf("frontier: %w", err)
+			sc.cond.Broadcast()
+			return FrontierItem{}, false
+		}
+		if ok {
+			return item, true
+		}
+		if sc.pending == 0 {
+			return FrontierItem{}, false
+		}
+		sc.cond.Wait()
+	}
+}
 
-			// Краулим страницу
-			crawler := NewHTTPCrawler(sc.config)
-			pageResult, err := crawler.CrawlPage(ctx, currentURL)
-			if err != nil {
-				log.Printf("Ошибка при краулинге %s: %v", currentURL, err)
-				result.Errors[currentURL] = err
-				continue
-			}
+// processItem краулит один URL из очереди и добавляет в очередь найденные ссылки
+func (sc *SiteCrawler) processItem(ctx context.Context, item FrontierItem, collector *resultCollector) {
+	currentURL := item.URL
+	log.Printf("Обработка URL: %s", currentURL)
 
+	// Резервируем место под страницу с учетом лимита.
+	// Оставшиеся URL вычитываются из очереди без загрузки.
+	if !collector.reserve() {
+		log.Printf("Достигнут лимит страниц (%d), пропускаем URL: %s", sc.maxPages, currentURL)
+		return
+	}
+
+	// Проверяем правила robots.txt
+	if rule, blocked := sc.blockedByRobots(ctx, item.fetchURL()); blocked {
+		log.Printf("URL запрещен robots.txt (%s): %s", rule, currentURL)
+		collector.release()
+		collector.addBlocked(currentURL, rule)
+		return
+	}
+
+	// Краулим страницу
+	pageResult, err := sc.crawler.CrawlPage(ctx, item.fetchURL())
+	if err != nil && pageResult == nil {
+		log.Printf("Ошибка при краулинге %s: %v", currentURL, err)
+		collector.release()
+		// Ошибки из-за отмены краулинга не относятся к странице
+		if ctx.Err() == nil {
+			collector.addError(currentURL, err)
+		}
+		return
+	}
+	pageResult.Depth = item.Depth
+	pageResult.ParentURL = item.ParentURL
+	pageResult.Anchor = item.Anchor
+
+	target := sc.redirectTarget(pageResult)
+	if isRedirectError(err) {
+		// Цепочка не дошла до конечной страницы: сохраняем ее под запрошенным URL
+		target = ""
+	}
+	if target == currentURL {
+		// Редирект между вариантами написания одного нормализованного URL
+		// (например, добавление завершающего слэша): содержимое относится к этому же URL
+		pageResult.StatusCode = pageResult.FinalStatusCode
+		pageResult.RedirectChain = nil
+		target = ""
+	}
+
+	if target != "" && currentURL == sc.start {
+		sc.adoptSiteHost(target)
+	}
+
+	if target != "" {
+		// Запрошенный URL сохраняется как редирект, содержимое — под конечным URL
+		log.Printf("URL %s перенаправляет на %s (переходов: %d)", currentURL, pageResult.FinalURL, len(pageResult.RedirectChain))
+		collector.addPage(currentURL, newRedirectRecord(pageResult))
+		item = FrontierItem{URL: target, Depth: item.Depth, ParentURL: item.ParentURL, Anchor: item.Anchor}
+		pageResult = sc.claimRedirectTarget(target, pageResult, collector)
+		if pageResult != nil && pageResult.Error != nil {
+			collector.addError(target, pageResult.Error)
+		}
+	} else {
+		if err != nil {
+			// Ответы 3xx, 4xx и 5xx сохраняются как страницы со статусом
+			log.Printf("URL %s вернул статус %d: %v", currentURL, pageResult.StatusCode, err)
+			collector.addError(currentURL, err)
+		} else {
 			log.Printf("Успешно обработан URL %s. Найдено ссылок: %d", currentURL, len(pageResult.Links))
+		}
+		collector.addPage(currentURL, pageResult)
+	}
+
+	// Не переходим по ссылкам страниц на максимальной глубине и страниц с nofollow
+	if pageResult != nil {
+		switch {
+		case sc.maxDepth >= 0 && item.Depth >= sc.maxDepth:
+			log.Printf("Достигнута максимальная глубина (%d) на %s", sc.maxDepth, item.URL)
+		case pageResult.Robots.NoFollow:
+			log.Printf("Страница %s запрещает переход по ссылкам (nofollow)", item.URL)
+			sc.enqueueCanonical(item, pageResult)
+			sc.enqueueHreflang(item, pageResult)
+		default:
+			sc.enqueueLinks(item, pageResult, collector)
+			sc.enqueueCanonical(item, pageResult)
+			sc.enqueueHreflang(item, pageResult)
+		}
+	}
+}
 
-			// Сохраняем результат
-			result.Pages[currentURL] = pageResult
-			result.TotalPages++
+// redirectTarget возвращает нормализованный конечный URL страницы с редиректом
+// или пустую строку, если редиректа не было
+func (sc *SiteCrawler) redirectTarget(page *CrawlerResult) string {
+	if len(page.RedirectChain) == 0 {
+		return ""
+	}
+	target, err := sc.normalizer.Normalize(nil, page.FinalURL)
+	if err != nil {
+		return ""
+	}
+	return target
+}
 
-			// Обрабатываем найденные ссылки
-			for _, link := range pageResult.Links {
-				// Обрабатываем относительные URL
-				if strings.HasPrefix(link, "/") {
-					link = fmt.Sprintf("%s://%s%s", sc.baseURL.Scheme, sc.baseURL.Host, link)
-				}
+// adoptSiteHost делает хост конечного URL редиректа начальной страницы хостом сайта,
+// если он относится к тому же регистрируемому домену. Вызывается до запуска воркеров.
+func (sc *SiteCrawler) adoptSiteHost(target string) {
+	u, err := url.Parse(target)
+	if err != nil || classifyHost(sc.baseURL, u) != LinkSubdomain {
+		return
+	}
+	log.Printf("Начальный URL перенаправляет на хост %s, сканируем его", u.Host)
+	base := *sc.baseURL
+	base.Scheme = u.Scheme
+	base.Host = u.Host
+	sc.baseURL = &base
+}
 
-				if sc.isValidInternalURL(link) {
-					log.Printf("Найдена валидная внутренняя ссылка: %s", link)
-					sc.visitedLock.Lock()
-					if !sc.visited[link] {
-						sc.visited[link] = true
-						select {
-						case sc.queue <- link:
-							log.Printf("Добавлена новая ссылка в очередь: %s", link)
-						default:
-							log.Printf("Очередь переполнена, пропускаем URL: %s", link)
-						}
-					}
-					sc.visitedLock.Unlock()
-				} else {
-					log.Printf("Пропущена невалидная или внешняя ссылка: %s", link)
-				}
+// claimRedirectTarget сохраняет содержимое конечной страницы редиректа под ее URL.
+// Место, зарезервированное под запрошенный URL, переходит к конечной странице:
+// записи о редиректах в лимит страниц не входят.
+// Возвращает nil и освобождает место, если конечный URL внешний или уже в очереди.
+func (sc *SiteCrawler) claimRedirectTarget(target string, page *CrawlerResult, collector *resultCollector) *CrawlerResult {
+	if !sc.isValidInternalURL(target) || !sc.markVisited(target) {
+		collector.release()
+		return nil
+	}
+
+	content := *page
+	content.URL = target
+	content.StatusCode = page.FinalStatusCode
+	content.RedirectChain = nil
+	collector.addPage(target, &content)
+	return &content
+}
+
+// newRedirectRecord создает запись о запрошенном URL, вернувшем редирект
+func newRedirectRecord(page *CrawlerResult) *CrawlerResult {
+	return &CrawlerResult{
+		URL:             page.URL,
+		StatusCode:      page.StatusCode,
+		FinalURL:        page.FinalURL,
+		FinalStatusCode: page.FinalStatusCode,
+		RedirectChain:   page.RedirectChain,
+		Depth:           page.Depth,
+		ParentURL:       page.ParentURL,
+		Anchor:          page.Anchor,
+	}
+}
+
+// isRedirectError проверяет, что редирект не удалось проследить до конечной страницы
+func isRedirectError(err error) bool {
+	return errors.Is(err, ErrRedirectLoop) || errors.Is(err, ErrTooManyRedirects) || errors.Is(err, ErrBlockedByRobots)
+}
+
+// enqueue добавляет URL в очередь, если он еще не был добавлен ранее
+func (sc *SiteCrawler) enqueue(item FrontierItem) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	// URL не отмечается посещенным, чтобы его можно было добавить, когда очередь освободится
+	if sc.config.MaxQueueSize > 0 && sc.frontier.Len() >= sc.config.MaxQueueSize {
+		if _, ok := sc.visited[fingerprint(item.URL)]; !ok {
+			sc.dropped++
+			log.Printf("Очередь переполнена (%d URL), пропускаем URL: %s", sc.config.MaxQueueSize, item.URL)
+		}
+		return
+	}
+	if !sc.visitLocked(item.URL) {
+		return
+	}
+
+	if err := sc.frontier.Push(item); err != nil {
+		log.Printf("Ошибка записи в очередь, пропускаем URL %s: %v", item.URL, err)
+		return
+	}
+	sc.pending++
+	sc.cond.Signal()
+	log.Printf("Добавлена новая ссылка в очередь: %s (глубина %d)", item.URL, item.Depth)
+}
+
+// markVisited отмечает URL как посещенный. Возвращает false, если URL уже был добавлен.
+func (sc *SiteCrawler) markVisited(pageURL string) bool {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	return sc.visitLocked(pageURL)
+}
+
+// visitLocked отмечает URL как посещенный. Вызывается под sc.mu.
+func (sc *SiteCrawler) visitLocked(pageURL string) bool {
+	key := fingerprint(pageURL)
+	if _, ok := sc.visited[key]; ok {
+		return false
+	}
+	sc.visited[key] = struct{}{}
+	return true
+}
+
+// finishItem отмечает URL как обработанный и будит воркеров,
+// если очередь пуста и других URL в обработке нет
+func (sc *SiteCrawler) finishItem() {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	sc.pending--
+	if sc.pending == 0 {
+		sc.cond.Broadcast()
+	}
+}
+
+// newFrontier создает очередь URL согласно конфигурации
+func (sc *SiteCrawler) newFrontier() (Frontier, error) {
+	if sc.config.FrontierDir != "" {
+		log.Printf("Используем очередь на диске в каталоге %s", sc.config.FrontierDir)
+		return NewDiskFrontier(sc.config.FrontierDir)
+	}
+	return NewMemoryFrontier(), nil
+}
+
+// fingerprint возвращает 64-битный отпечаток URL
+func fingerprint(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	return h.Sum64()
+}
+
+// enqueueLinks добавляет в очередь внутренние ссылки страницы
+func (sc *SiteCrawler) enqueueLinks(item FrontierItem, page *CrawlerResult, collector *resultCollector) {
+	for _, cl := range page.Links {
+		if cl.Type != LinkInternal {
+			log.Printf("Пропущена ссылка типа %s: %s", cl.Type, cl.Href)
+			continue
+		}
+
+		link, err := sc.normalizer.Normalize(nil, cl.URL)
+		if err != nil {
+			log.Printf("Пропущена невалидная ссылка: %s", cl.Href)
+			continue
+		}
+
+		// Запоминаем варианты написания, сведенные к одному URL
+		if cl.URL != link {
+			collector.addVariant(link, cl.URL)
+		}
+
+		if !sc.isValidInternalURL(link) && !(sc.config.CrawlResources && sc.isInternalResource(link)) {
+			log.Printf("Пропущена ссылка на файл: %s", link)
+			continue
+		}
+
+		log.Printf("Найдена валидная внутренняя ссылка: %s", link)
+		sc.enqueue(FrontierItem{
+			URL:       link,
+			FetchURL:  withoutFragment(cl.URL),
+			Depth:     item.Depth + 1,
+			ParentURL: item.URL,
+			Anchor:    cl.Anchor,
+		})
+	}
+}
+
+// enqueueCanonical добавляет в очередь внутренний canonical URL страницы,
+// чтобы проверить его ответ при анализе индексируемости
+func (sc *SiteCrawler) enqueueCanonical(item FrontierItem, page *CrawlerResult) {
+	canonical := sc.canonicalURL(page)
+	if canonical == "" || canonical == item.URL || !sc.isValidInternalURL(canonical) {
+		return
+	}
+	sc.enqueue(FrontierItem{
+		URL:       canonical,
+		Depth:     item.Depth + 1,
+		ParentURL: item.URL,
+	})
+}
+
+// discoverSitemaps находит sitemap сайта через robots.txt и стандартный адрес
+func (sc *SiteCrawler) discoverSitemaps(ctx context.Context, result *SiteCrawlerResult) {
+	var declared []string
+	if r, err := sc.robots.Get(ctx, sc.baseURL); err == nil {
+		declared = r.Sitemaps
+	}
+
+	fetcher := sitemap.NewFetcher(sc.client, sc.config.UserAgent)
+	found := fetcher.Discover(ctx, sc.baseURL, declared)
+
+	for _, u := range found.URLs {
+		loc, err := sc.normalizer.Normalize(nil, u.Loc)
+		if err != nil {
+			result.Sitemap.Errors[u.Sitemap] = fmt.Sprintf("invalid URL %q", u.Loc)
+			continue
+		}
+		if _, ok := sc.sitemapLocs[loc]; !ok {
+			sc.sitemapLocs[loc] = withoutFragment(u.Loc)
+		}
+		u.Loc = loc
+		sc.sitemapURLs = append(sc.sitemapURLs, u)
+	}
+	result.Sitemap.Sitemaps = found.Sitemaps
+	result.Sitemap.TotalURLs = len(sc.sitemapURLs)
+	for sitemapURL, err := range found.Errors {
+		result.Sitemap.Errors[sitemapURL] = err.Error()
+	}
+
+	log.Printf("Найдено sitemap: %d, URL в них: %d", len(found.Sitemaps), len(found.URLs))
+}
+
+// compareSitemap сравнивает URL из sitemap со ссылками и просканированными страницами
+func (sc *SiteCrawler) compareSitemap(result *SiteCrawlerResult) {
+	if len(sc.sitemapURLs) == 0 {
+		return
+	}
+
+	linked := make(map[string]bool)
+	for _, page := range result.Pages {
+		for _, l := range page.Links {
+			if link, err := sc.resolveLink(page, l.Href); err == nil {
+				linked[link] = true
 			}
+		}
+	}
+
+	inSitemap := make(map[string]bool, len(sc.sitemapURLs))
+	for _, u := range sc.sitemapURLs {
+		inSitemap[u.Loc] = true
+		if !linked[u.Loc] {
+			result.Sitemap.NotLinked = append(result.Sitemap.NotLinked, u.Loc)
+		}
+	}
+
+	// Редиректы, ошибки и файлы не должны быть в sitemap
+	for pageURL, page := range result.Pages {
+		if isContentPage(page) && !inSitemap[pageURL] {
+			result.Sitemap.MissingFromSitemap = append(result.Sitemap.MissingFromSitemap, pageURL)
+		}
+	}
+
+	sort.Strings(result.Sitemap.NotLinked)
+	sort.Strings(result.Sitemap.MissingFromSitemap)
+}
+
+// blockedByRobots проверяет URL по robots.txt и возвращает сработавшее правило
+func (sc *SiteCrawler) blockedByRobots(ctx context.Context, pageURL string) (string, bool) {
+	if sc.config.IgnoreRobotsTxt {
+		return "", false
+	}
+
+	allowed, rule, err := sc.robots.Test(ctx, pageURL)
+	if err != nil || allowed {
+		return "", false
+	}
+	return rule.String(), true
+}
+
+// calculateClickDepth вычисляет кратчайшую глубину клика от начального URL.
+// Порядок обхода воркерами не гарантирует кратчайший путь, поэтому глубина
+// пересчитывается по графу ссылок. Страницы, найденные только через sitemap, получают -1.
+func (sc *SiteCrawler) calculateClickDepth(result *SiteCrawlerResult) {
+	for _, page := range result.Pages {
+		page.Depth = -1
+	}
+
+	var queue []string
+	var visit func(pageURL string, depth int)
+	visit = func(pageURL string, depth int) {
+		page, ok := result.Pages[pageURL]
+		if !ok || page.Depth >= 0 {
+			return
+		}
+		page.Depth = depth
+		queue = append(queue, pageURL)
+		// Переход по редиректу не требует клика: конечная страница получает ту же глубину
+		if target := sc.redirectTarget(page); target != "" {
+			visit(target, depth)
+		}
+	}
+
+	visit(sc.start, 0)
+	for len(queue) > 0 {
+		current := result.Pages[queue[0]]
+		queue = queue[1:]
 
-			// Добавляем задержку между запросами
-			time.Sleep(sc.config.RequestDelay)
+		for _, l := range current.Links {
+			if link, err := sc.resolveLink(current, l.Href); err == nil {
+				visit(link, current.Depth+1)
+			}
 		}
 	}
 }
 
 // calculateStatistics вычисляет статистику по сайту
 func (sc *SiteCrawler) calculateStatistics(result *SiteCrawlerResult) {
+	contentPages := 0
 	for pageURL, page := range result.Pages {
+		result.Statistics.StatusCodes[page.StatusCode]++
+
+		// Распределение по глубине клика
+		result.Statistics.DepthDistribution[page.Depth]++
+
+		if page.MIMEType != "" {
+			result.Statistics.ContentTypes[page.MIMEType]++
+		}
+
+		if page.Attempts > 1 {
+			result.Statistics.FlakyPages = append(result.Statistics.FlakyPages, pageURL)
+		}
+
+		// Проверки содержимого имеют смысл только для страниц с ответом 200
+		if !isContentPage(page) {
+			continue
+		}
+		contentPages++
+
 		// Подсчет слов
 		result.Statistics.TotalWordCount += page.WordCount
 
@@ -245,20 +851,162 @@ func (sc *SiteCrawler) calculateStatistics(result *SiteCrawlerResult) {
 			result.Statistics.MissingTitle = append(result.Statistics.MissingTitle, pageURL)
 		}
 
-		// Подсчет внешних ссылок
+		if page.CharsetMismatch {
+			result.Statistics.CharsetMismatches = append(result.Statistics.CharsetMismatches, pageURL)
+		}
+
+		// Подсчет ссылок по типам и внешних доменов
 		for _, link := range page.Links {
-			parsedURL, err := url.Parse(link)
-			if err != nil {
+			result.Statistics.LinkTypes[link.Type]++
+			if link.Type != LinkExternal && link.Type != LinkSubdomain {
 				continue
 			}
-			if parsedURL.Host != sc.baseURL.Host {
+			if parsedURL, err := url.Parse(link.URL); err == nil {
 				result.Statistics.UniqueDomains[parsedURL.Host]++
 			}
 		}
 	}
 
+	sort.Strings(result.Statistics.FlakyPages)
+	sort.Strings(result.Statistics.CharsetMismatches)
+	sc.findBrokenLinks(result)
+
 	// Вычисляем среднее количество слов
-	if result.TotalPages > 0 {
-		result.Statistics.AverageWordCount = result.Statistics.TotalWordCount / result.TotalPages
+	if contentPages > 0 {
+		result.Statistics.AverageWordCount = result.Statistics.TotalWordCount / contentPages
+	}
+}
+
+// findBrokenLinks находит страницы с ответом 4xx/5xx и ссылки на них.
+// Ссылка на URL, цепочка редиректов которого заканчивается ответом 4xx/5xx,
+// тоже считается битой: для нее указывается статус конечной страницы.
+func (sc *SiteCrawler) findBrokenLinks(result *SiteCrawlerResult) {
+	broken := make(map[string]*BrokenLink)
+	for pageURL, page := range result.Pages {
+		statusCode := page.StatusCode
+		if len(page.RedirectChain) > 0 {
+			statusCode = page.FinalStatusCode
+		}
+		if statusCode >= 400 {
+			broken[pageURL] = &BrokenLink{URL: pageURL, StatusCode: statusCode}
+		}
+	}
+	if len(broken) == 0 {
+		return
+	}
+
+	for pageURL, page := range result.Pages {
+		for _, cl := range page.Links {
+			if cl.Type != LinkInternal {
+				continue
+			}
+			target, err := sc.normalizer.Normalize(nil, cl.URL)
+			if err != nil {
+				continue
+			}
+			if link, ok := broken[target]; ok {
+				link.Sources = append(link.Sources, LinkSource{
+					PageURL: pageURL,
+					Anchor:  cl.Anchor,
+				})
+			}
+		}
+	}
+
+	for _, link := range broken {
+		sort.Slice(link.Sources, func(i, j int) bool {
+			return link.Sources[i].PageURL < link.Sources[j].PageURL
+		})
+		result.Statistics.BrokenLinks = append(result.Statistics.BrokenLinks, *link)
+	}
+	sort.Slice(result.Statistics.BrokenLinks, func(i, j int) bool {
+		return result.Statistics.BrokenLinks[i].URL < result.Statistics.BrokenLinks[j].URL
+	})
+}
+
+// auditRedirects находит длинные цепочки редиректов, циклы, редиректы на 4xx
+// и внутренние ссылки на URL с редиректом
+func (sc *SiteCrawler) auditRedirects(result *SiteCrawlerResult) {
+	report := &result.Redirects
+	for pageURL, page := range result.Pages {
+		if page.JavaScriptRedirect != "" {
+			redirect := Redirect{
+				URL:      pageURL,
+				FinalURL: page.JavaScriptRedirect,
+				Hops: []RedirectHop{{
+					URL:        page.FinalURL,
+					StatusCode: page.FinalStatusCode,
+					Location:   page.JavaScriptRedirect,
+					Type:       RedirectJavaScript,
+				}},
+			}
+			// Статус известен, если адрес перехода был просканирован
+			if target, err := sc.normalizer.Normalize(nil, page.JavaScriptRedirect); err == nil {
+				if linked, ok := result.Pages[target]; ok {
+					redirect.FinalStatusCode = linked.StatusCode
+				}
+			}
+			report.JavaScript = append(report.JavaScript, redirect)
+		}
+		if len(page.RedirectChain) == 0 {
+			continue
+		}
+		redirect := Redirect{
+			URL:             pageURL,
+			FinalURL:        page.FinalURL,
+			FinalStatusCode: page.FinalStatusCode,
+			Hops:            page.RedirectChain,
+		}
+		if len(page.RedirectChain) > 1 {
+			report.Chains = append(report.Chains, redirect)
+		}
+		if errors.Is(page.Error, ErrRedirectLoop) {
+			report.Loops = append(report.Loops, redirect)
+		} else if page.FinalStatusCode >= 400 && page.FinalStatusCode < 500 {
+			report.ToClientErrors = append(report.ToClientErrors, redirect)
+		}
 	}
+
+	for pageURL, page := range result.Pages {
+		if !isContentPage(page) {
+			continue
+		}
+		reported := make(map[string]bool)
+		for _, cl := range page.Links {
+			if cl.Type != LinkInternal || reported[cl.URL] {
+				continue
+			}
+			target, err := sc.normalizer.Normalize(nil, cl.URL)
+			if err != nil {
+				continue
+			}
+			if linked, ok := result.Pages[target]; ok && len(linked.RedirectChain) > 0 {
+				reported[cl.URL] = true
+				report.LinkedRedirects = append(report.LinkedRedirects, RedirectedLink{
+					PageURL:  pageURL,
+					Anchor:   cl.Anchor,
+					URL:      cl.URL,
+					FinalURL: linked.FinalURL,
+				})
+			}
+		}
+	}
+
+	for _, redirects := range [][]Redirect{report.Chains, report.Loops, report.ToClientErrors, report.JavaScript} {
+		sort.Slice(redirects, func(i, j int) bool {
+			return redirects[i].URL < redirects[j].URL
+		})
+	}
+	sort.Slice(report.LinkedRedirects, func(i, j int) bool {
+		a, b := report.LinkedRedirects[i], report.LinkedRedirects[j]
+		if a.PageURL != b.PageURL {
+			return a.PageURL < b.PageURL
+		}
+		return a.URL < b.URL
+	})
+}
+
+// isContentPage проверяет, что HTML-страница успешно загружена и ее содержимое разобрано
+func isContentPage(page *CrawlerResult) bool {
+	return page.StatusCode == http.StatusOK && len(page.RedirectChain) == 0 && isHTMLType(page.MIMEType)
 }
@@ -2,8 +2,15 @@ package crawler
 
 import (
 	"context"
+	"errors"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -121,6 +128,11 @@ func TestSiteCrawler_IsValidInternalURL(t *testing.T) {
 		{"different domain", "https://other.com/page", false},
 		{"file", "https://example.com/file.pdf", false},
 		{"image", "https://example.com/image.jpg", false},
+		{"html page", "https://example.com/index.html", true},
+		{"php page", "https://example.com/catalog.php?id=1", true},
+		{"aspx page", "https://example.com/Default.aspx", true},
+		{"jsp page", "https://example.com/shop/item.jsp", true},
+		{"dotted path segment", "https://example.com/v1.2/docs", true},
 		{"invalid url", "not a url", false},
 	}
 
@@ -133,3 +145,796 @@ func TestSiteCrawler_IsValidInternalURL(t *testing.T) {
 		})
 	}
 }
+
+func TestSiteCrawler_CalculateClickDepth(t *testing.T) {
+	crawler, err := NewSiteCrawler("https://example.com", DefaultConfig(), 10, 3)
+	if err != nil {
+		t.Fatalf("Failed to create crawler: %v", err)
+	}
+
+	// Страница /deep найдена воркером раньше через длинный путь, но есть и короткий
+	result := &SiteCrawlerResult{
+		Pages: map[string]*CrawlerResult{
+			"https://example.com/":        {URL: "https://example.com/", Links: []Link{{Href: "/a"}, {Href: "/deep"}}},
+			"https://example.com/a":       {URL: "https://example.com/a", Links: []Link{{Href: "/b"}}, Depth: 1},
+			"https://example.com/b":       {URL: "https://example.com/b", Links: []Link{{Href: "/deep"}}, Depth: 2},
+			"https://example.com/deep":    {URL: "https://example.com/deep", Depth: 3},
+			"https://example.com/sitemap": {URL: "https://example.com/sitemap"},
+		},
+	}
+
+	crawler.calculateClickDepth(result)
+
+	expected := map[string]int{
+		"https://example.com/":        0,
+		"https://example.com/a":       1,
+		"https://example.com/b":       2,
+		"https://example.com/deep":    1,
+		"https://example.com/sitemap": -1,
+	}
+	for pageURL, depth := range expected {
+		if got := result.Pages[pageURL].Depth; got != depth {
+			t.Errorf("Depth of %s = %d; want %d", pageURL, got, depth)
+		}
+	}
+}
+
+func TestSiteCrawler_CalculateClickDepth_Redirects(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			http.Redirect(w, r, "/ru", http.StatusMovedPermanently)
+		case "/ru":
+			w.Write([]byte(`<html><body><a href="/ru/about">О нас</a><a href="/old">Каталог</a></body></html>`))
+		case "/old":
+			http.Redirect(w, r, "/catalog", http.StatusMovedPermanently)
+		case "/catalog":
+			w.Write([]byte(`<html><body><a href="/catalog/item">Товар</a></body></html>`))
+		case "/ru/about", "/catalog/item":
+			w.Write([]byte(`<html><body></body></html>`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.RequestDelay = 0
+	config.IgnoreSitemaps = true
+
+	crawler, err := NewSiteCrawler(server.URL, config, 10, 5)
+	if err != nil {
+		t.Fatalf("Failed to create crawler: %v", err)
+	}
+	result, err := crawler.CrawlSite(context.Background())
+	if err != nil {
+		t.Fatalf("CrawlSite failed: %v", err)
+	}
+
+	// Начальный URL и ссылка ведут через редиректы, конечные страницы получают ту же глубину
+	expected := map[string]int{
+		server.URL + "/":             0,
+		server.URL + "/ru":           0,
+		server.URL + "/ru/about":     1,
+		server.URL + "/old":          1,
+		server.URL + "/catalog":      1,
+		server.URL + "/catalog/item": 2,
+	}
+	for pageURL, depth := range expected {
+		page, ok := result.Pages[pageURL]
+		if !ok {
+			t.Errorf("Expected %s to be crawled", pageURL)
+			continue
+		}
+		if page.Depth != depth {
+			t.Errorf("Depth of %s = %d; want %d", pageURL, page.Depth, depth)
+		}
+	}
+	if count := result.Statistics.DepthDistribution[-1]; count != 0 {
+		t.Errorf("Expected no unreachable pages, got %d", count)
+	}
+}
+
+func TestSiteCrawler_CrawlSite_Termination(t *testing.T) {
+	server := newSyntheticSite(t, 30)
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.RequestDelay = 0
+	config.IgnoreSitemaps = true
+
+	crawler, err := NewSiteCrawler(server.URL, config, 100, -1)
+	if err != nil {
+		t.Fatalf("Failed to create crawler: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	result, err := crawler.CrawlSite(ctx)
+	if err != nil {
+		t.Fatalf("CrawlSite failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("Expected crawl to finish when frontier is empty, took %v", elapsed)
+	}
+	// Главная страница и 30 страниц /page/N
+	if result.TotalPages != 31 {
+		t.Errorf("Expected 31 pages, got %d", result.TotalPages)
+	}
+}
+
+func TestSiteCrawler_CrawlSite_PartialResults(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			w.Write([]byte(`<html><head><title>Home</title></head><body>
+				<a href="/slow">Slow</a>
+			</body></html>`))
+		case "/slow":
+			// Страница отвечает дольше, чем длится краулинг
+			select {
+			case <-r.Context().Done():
+			case <-time.After(5 * time.Second):
+			}
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.RequestDelay = 0
+	config.IgnoreSitemaps = true
+
+	crawler, err := NewSiteCrawler(server.URL, config, 10, 3)
+	if err != nil {
+		t.Fatalf("Failed to create crawler: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+
+	result, err := crawler.CrawlSite(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected context.DeadlineExceeded, got %v", err)
+	}
+	if result == nil {
+		t.Fatal("Expected partial result, got nil")
+	}
+	if result.EndTime.IsZero() {
+		t.Errorf("Expected EndTime to be set")
+	}
+	if result.TotalPages != 1 {
+		t.Errorf("Expected 1 crawled page, got %d", result.TotalPages)
+	}
+	if result.Statistics.TotalWordCount == 0 {
+		t.Errorf("Expected statistics to be calculated for partial result")
+	}
+	if len(result.Errors) != 0 {
+		t.Errorf("Expected cancelled fetches not to be recorded as errors, got %v", result.Errors)
+	}
+}
+
+func TestSiteCrawler_CrawlSite_Deduplication(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			w.Write([]byte(`<html><head><title>Home</title></head><body>
+				<a href="/about">About</a>
+				<a href="/about/">About</a>
+				<a href="/about#team">Team</a>
+				<a href="/about?utm_source=x">About</a>
+			</body></html>`))
+		case "/about", "/about/":
+			w.Write([]byte(`<html><head><title>About</title></head><body>About</body></html>`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.RequestDelay = 0
+	config.IgnoreSitemaps = true
+
+	crawler, err := NewSiteCrawler(server.URL, config, 10, 3)
+	if err != nil {
+		t.Fatalf("Failed to create crawler: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := crawler.CrawlSite(ctx)
+	if err != nil {
+		t.Fatalf("CrawlSite failed: %v", err)
+	}
+	if result.TotalPages != 2 {
+		t.Errorf("Expected 2 pages, got %d: %v", result.TotalPages, result.Pages)
+	}
+	if variants := result.Normalization.Variants[server.URL+"/about"]; len(variants) != 3 {
+		t.Errorf("Expected 3 variants of /about, got %v", variants)
+	}
+	if len(result.Normalization.Rules) == 0 {
+		t.Errorf("Expected normalization rules in result")
+	}
+}
+
+func TestSiteCrawler_CrawlSite_SitemapMaxDepth(t *testing.T) {
+	var serverURL string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/sitemap.xml":
+			w.Write([]byte(`<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9"><url><loc>` + serverURL + `/deep</loc></url></urlset>`))
+		case "/":
+			w.Write([]byte(`<html><head><title>Home</title></head><body><a href="/about">О нас</a></body></html>`))
+		default:
+			w.Write([]byte(`<html><head><title>Page</title></head><body><a href="` + r.URL.Path + `/next">Дальше</a></body></html>`))
+		}
+	}))
+	defer server.Close()
+	serverURL = server.URL
+
+	tests := []struct {
+		maxDepth int
+		expected []string
+	}{
+		{0, []string{"/", "/deep"}},
+		{1, []string{"/", "/about", "/deep"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(strconv.Itoa(tt.maxDepth), func(t *testing.T) {
+			config := DefaultConfig()
+			config.RequestDelay = 0
+
+			crawler, err := NewSiteCrawler(server.URL+"/", config, 20, tt.maxDepth)
+			if err != nil {
+				t.Fatalf("Failed to create crawler: %v", err)
+			}
+			result, err := crawler.CrawlSite(context.Background())
+			if err != nil {
+				t.Fatalf("CrawlSite failed: %v", err)
+			}
+
+			// Ссылки со страниц из sitemap не обходятся сверх максимальной глубины
+			var crawled []string
+			for pageURL := range result.Pages {
+				crawled = append(crawled, strings.TrimPrefix(pageURL, server.URL))
+			}
+			sort.Strings(crawled)
+			if !reflect.DeepEqual(crawled, tt.expected) {
+				t.Errorf("Expected pages %v, got %v", tt.expected, crawled)
+			}
+		})
+	}
+}
+
+func TestSiteCrawler_CrawlSite_TrailingSlash(t *testing.T) {
+	var mu sync.Mutex
+	var requested []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requested = append(requested, r.URL.Path)
+		mu.Unlock()
+
+		// Сайт отвечает только по адресам со слэшем
+		switch r.URL.Path {
+		case "/":
+			w.Write([]byte(`<html><head><title>Home</title></head><body><a href="/about/#team">О нас</a></body></html>`))
+		case "/about/":
+			w.Write([]byte(`<html><head><title>About</title></head><body></body></html>`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.RequestDelay = 0
+	config.IgnoreSitemaps = true
+	config.IgnoreRobotsTxt = true
+
+	crawler, err := NewSiteCrawler(server.URL+"/", config, 10, 3)
+	if err != nil {
+		t.Fatalf("Failed to create crawler: %v", err)
+	}
+	result, err := crawler.CrawlSite(context.Background())
+	if err != nil {
+		t.Fatalf("CrawlSite failed: %v", err)
+	}
+
+	// Страница хранится под нормализованным URL, а загружается по адресу из ссылки
+	page := result.Pages[server.URL+"/about"]
+	if page == nil || page.StatusCode != http.StatusOK || page.Title != "About" {
+		t.Fatalf("Expected /about/ to be crawled, got %+v", page)
+	}
+	if page.URL != server.URL+"/about/" {
+		t.Errorf("Expected page URL as linked, got %s", page.URL)
+	}
+	if len(result.Statistics.BrokenLinks) != 0 {
+		t.Errorf("Expected no broken links, got %+v", result.Statistics.BrokenLinks)
+	}
+	if expected := []string{"/", "/about/"}; !reflect.DeepEqual(requested, expected) {
+		t.Errorf("Expected requests %v, got %v", expected, requested)
+	}
+}
+
+func TestSiteCrawler_CrawlSite_BrokenLinks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			w.Write([]byte(`<html><head><title>Home</title></head><body>
+				<a href="/missing">Старая страница</a>
+				<a href="/error">Ошибка</a>
+				<a href="/ok">OK</a>
+			</body></html>`))
+		case "/ok":
+			w.Write([]byte(`<html><head><title>OK</title></head><body><a href="/missing">Снова</a></body></html>`))
+		case "/error":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.RequestDelay = 0
+	config.IgnoreSitemaps = true
+
+	crawler, err := NewSiteCrawler(server.URL, config, 10, 3)
+	if err != nil {
+		t.Fatalf("Failed to create crawler: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := crawler.CrawlSite(ctx)
+	if err != nil {
+		t.Fatalf("CrawlSite failed: %v", err)
+	}
+
+	if result.TotalPages != 4 {
+		t.Errorf("Expected 4 pages including non-200, got %d", result.TotalPages)
+	}
+	if page := result.Pages[server.URL+"/missing"]; page == nil || page.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected /missing to be recorded with status 404, got %+v", page)
+	}
+	if result.Statistics.StatusCodes[http.StatusNotFound] != 1 || result.Statistics.StatusCodes[http.StatusInternalServerError] != 1 {
+		t.Errorf("Unexpected status code distribution: %v", result.Statistics.StatusCodes)
+	}
+
+	var statusErr *StatusError
+	if !errors.As(result.Errors[server.URL+"/error"], &statusErr) || statusErr.StatusCode != http.StatusInternalServerError {
+		t.Errorf("Expected *StatusError with 500 for /error, got %v", result.Errors[server.URL+"/error"])
+	}
+
+	if len(result.Statistics.BrokenLinks) != 2 {
+		t.Fatalf("Expected 2 broken links, got %+v", result.Statistics.BrokenLinks)
+	}
+	missing := result.Statistics.BrokenLinks[1]
+	if missing.URL != server.URL+"/missing" || len(missing.Sources) != 2 {
+		t.Fatalf("Expected /missing with 2 sources, got %+v", missing)
+	}
+	if missing.Sources[0].PageURL != server.URL+"/" || missing.Sources[0].Anchor != "Старая страница" {
+		t.Errorf("Unexpected source %+v", missing.Sources[0])
+	}
+	if len(result.Statistics.MissingTitle) != 0 {
+		t.Errorf("Expected non-200 pages to be excluded from content checks, got %v", result.Statistics.MissingTitle)
+	}
+}
+
+func TestSiteCrawler_CrawlSite_BrokenRedirectLinks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			w.Write([]byte(`<html><head><title>Home</title></head><body><a href="/old">Старый адрес</a></body></html>`))
+		case "/old":
+			http.Redirect(w, r, "/gone", http.StatusMovedPermanently)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.RequestDelay = 0
+	config.IgnoreSitemaps = true
+
+	crawler, err := NewSiteCrawler(server.URL, config, 10, 3)
+	if err != nil {
+		t.Fatalf("Failed to create crawler: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := crawler.CrawlSite(ctx)
+	if err != nil {
+		t.Fatalf("CrawlSite failed: %v", err)
+	}
+
+	// Ссылка ведет на редирект, который заканчивается 404: она битая со страницы-источника
+	var old *BrokenLink
+	for i, link := range result.Statistics.BrokenLinks {
+		if link.URL == server.URL+"/old" {
+			old = &result.Statistics.BrokenLinks[i]
+		}
+	}
+	if old == nil {
+		t.Fatalf("Expected /old to be reported as broken, got %+v", result.Statistics.BrokenLinks)
+	}
+	if old.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected final status 404 for /old, got %d", old.StatusCode)
+	}
+	if len(old.Sources) != 1 || old.Sources[0].PageURL != server.URL+"/" || old.Sources[0].Anchor != "Старый адрес" {
+		t.Errorf("Expected /old to be linked from the home page, got %+v", old.Sources)
+	}
+}
+
+func TestSiteCrawler_CrawlSite_Resources(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			w.Write([]byte(`<html><head><title>Home</title></head><body>
+				<a href="/price.pdf">Прайс</a>
+				<a href="/old.pdf">Старый прайс</a>
+				<a href="/about">О нас</a>
+			</body></html>`))
+		case "/about":
+			w.Write([]byte(`<html><head><title>About</title></head><body></body></html>`))
+		case "/price.pdf":
+			w.Header().Set("Content-Type", "application/pdf")
+			w.Write([]byte("%PDF-1.7 <a href=\"/hidden\">"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name           string
+		crawlResources bool
+		expectedPages  int
+	}{
+		{"resources skipped", false, 2},
+		{"resources crawled", true, 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.RequestDelay = 0
+			config.IgnoreSitemaps = true
+			config.CrawlResources = tt.crawlResources
+
+			crawler, err := NewSiteCrawler(server.URL, config, 10, 3)
+			if err != nil {
+				t.Fatalf("Failed to create crawler: %v", err)
+			}
+
+			result, err := crawler.CrawlSite(context.Background())
+			if err != nil {
+				t.Fatalf("CrawlSite failed: %v", err)
+			}
+			if result.TotalPages != tt.expectedPages {
+				t.Fatalf("Expected %d pages, got %d", tt.expectedPages, result.TotalPages)
+			}
+			if !tt.crawlResources {
+				return
+			}
+
+			pdf := result.Pages[server.URL+"/price.pdf"]
+			if pdf == nil || pdf.StatusCode != http.StatusOK || pdf.MIMEType != "application/pdf" {
+				t.Fatalf("Expected PDF to be recorded with status and type, got %+v", pdf)
+			}
+			if result.Statistics.ContentTypes["application/pdf"] != 1 || result.Statistics.ContentTypes["text/html"] != 2 {
+				t.Errorf("Unexpected content types: %v", result.Statistics.ContentTypes)
+			}
+			if len(result.Statistics.MissingTitle) != 0 {
+				t.Errorf("Expected resources to be excluded from content checks, got %v", result.Statistics.MissingTitle)
+			}
+			if len(result.Statistics.BrokenLinks) != 1 || result.Statistics.BrokenLinks[0].URL != server.URL+"/old.pdf" {
+				t.Errorf("Expected broken link to /old.pdf, got %+v", result.Statistics.BrokenLinks)
+			}
+		})
+	}
+}
+
+func TestSiteCrawler_CrawlSite_Redirects(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			w.Write([]byte(`<html><head><title>Home</title></head><body>
+				<a href="/old">Старый адрес</a>
+				<a href="/chain">Цепочка</a>
+				<a href="/gone">Удалено</a>
+				<a href="/loop">Цикл</a>
+				<a href="/docs/">Документация</a>
+				<a href="/script">Скрипт</a>
+			</body></html>`))
+		case "/script":
+			w.Write([]byte(`<html><head><title>Script</title><script>location.replace("/new")</script></head><body></body></html>`))
+		case "/old":
+			http.Redirect(w, r, "/new", http.StatusMovedPermanently)
+		case "/chain":
+			http.Redirect(w, r, "/old", http.StatusFound)
+		case "/new":
+			w.Write([]byte(`<html><head><title>New</title></head><body><a href="/">Главная</a></body></html>`))
+		case "/gone":
+			http.Redirect(w, r, "/missing", http.StatusMovedPermanently)
+		case "/loop":
+			http.Redirect(w, r, "/loop", http.StatusFound)
+		case "/docs":
+			// Вариант написания того же нормализованного URL
+			http.Redirect(w, r, "/docs/", http.StatusMovedPermanently)
+		case "/docs/":
+			w.Write([]byte(`<html><head><title>Docs</title></head><body></body></html>`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.RequestDelay = 0
+	config.IgnoreSitemaps = true
+
+	crawler, err := NewSiteCrawler(server.URL, config, 20, 3)
+	if err != nil {
+		t.Fatalf("Failed to create crawler: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := crawler.CrawlSite(ctx)
+	if err != nil {
+		t.Fatalf("CrawlSite failed: %v", err)
+	}
+
+	old := result.Pages[server.URL+"/old"]
+	if old == nil || old.StatusCode != http.StatusMovedPermanently || old.FinalURL != server.URL+"/new" {
+		t.Fatalf("Expected /old to be recorded as a redirect to /new, got %+v", old)
+	}
+	if page := result.Pages[server.URL+"/new"]; page == nil || page.Title != "New" || len(page.RedirectChain) != 0 {
+		t.Errorf("Expected content of /new under its own URL, got %+v", page)
+	}
+	if page := result.Pages[server.URL+"/docs"]; page == nil || page.StatusCode != http.StatusOK || page.Title != "Docs" {
+		t.Errorf("Expected trailing slash redirect to keep content under /docs, got %+v", page)
+	}
+
+	report := result.Redirects
+	if len(report.Chains) != 1 || report.Chains[0].URL != server.URL+"/chain" || len(report.Chains[0].Hops) != 2 {
+		t.Errorf("Expected /chain as the only multi-hop chain, got %+v", report.Chains)
+	}
+	if len(report.Loops) != 1 || report.Loops[0].URL != server.URL+"/loop" {
+		t.Errorf("Expected /loop to be reported as a loop, got %+v", report.Loops)
+	}
+	if !errors.Is(result.Errors[server.URL+"/loop"], ErrRedirectLoop) {
+		t.Errorf("Expected ErrRedirectLoop for /loop, got %v", result.Errors[server.URL+"/loop"])
+	}
+	if len(report.ToClientErrors) != 1 || report.ToClientErrors[0].URL != server.URL+"/gone" || report.ToClientErrors[0].FinalStatusCode != http.StatusNotFound {
+		t.Errorf("Expected /gone to be reported as a redirect to 404, got %+v", report.ToClientErrors)
+	}
+	if len(report.LinkedRedirects) != 4 {
+		t.Fatalf("Expected 4 internal links to redirects, got %+v", report.LinkedRedirects)
+	}
+	if link := report.LinkedRedirects[3]; link.URL != server.URL+"/old" || link.Anchor != "Старый адрес" || link.FinalURL != server.URL+"/new" {
+		t.Errorf("Unexpected linked redirect %+v", link)
+	}
+	if len(result.Statistics.MissingTitle) != 0 {
+		t.Errorf("Expected redirects to be excluded from content checks, got %v", result.Statistics.MissingTitle)
+	}
+	if page := result.Pages[server.URL+"/script"]; page == nil || page.Title != "Script" || len(page.RedirectChain) != 0 {
+		t.Errorf("Expected content of /script under its own URL, got %+v", page)
+	}
+	if len(report.JavaScript) != 1 || report.JavaScript[0].URL != server.URL+"/script" ||
+		report.JavaScript[0].FinalURL != server.URL+"/new" || report.JavaScript[0].FinalStatusCode != http.StatusOK {
+		t.Errorf("Expected /script to be reported as a JavaScript redirect to /new, got %+v", report.JavaScript)
+	}
+}
+
+func TestSiteCrawler_CrawlSite_RedirectMaxPages(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			http.Redirect(w, r, "/home", http.StatusMovedPermanently)
+		case "/home":
+			w.Write([]byte(`<html><head><title>Home</title></head><body><a href="/about">О нас</a></body></html>`))
+		default:
+			w.Write([]byte(`<html><head><title>Page</title></head><body></body></html>`))
+		}
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.RequestDelay = 0
+	config.IgnoreSitemaps = true
+
+	// Запись о редиректе не занимает место в лимите страниц
+	crawler, err := NewSiteCrawler(server.URL, config, 1, 3)
+	if err != nil {
+		t.Fatalf("Failed to create crawler: %v", err)
+	}
+	result, err := crawler.CrawlSite(context.Background())
+	if err != nil {
+		t.Fatalf("CrawlSite failed: %v", err)
+	}
+
+	if page := result.Pages[server.URL+"/"]; page == nil || page.StatusCode != http.StatusMovedPermanently {
+		t.Errorf("Expected start URL to be recorded as a redirect, got %+v", page)
+	}
+	if page := result.Pages[server.URL+"/home"]; page == nil || page.Title != "Home" {
+		t.Errorf("Expected content of the redirect target, got %+v", page)
+	}
+	if _, ok := result.Pages[server.URL+"/about"]; ok || result.TotalPages != 2 {
+		t.Errorf("Expected only the redirect and its target, got %d pages", result.TotalPages)
+	}
+}
+
+func TestSiteCrawler_CrawlSite_StartRedirectToWWW(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Host == "example.com" {
+			http.Redirect(w, r, "http://www.example.com"+r.URL.Path, http.StatusMovedPermanently)
+			return
+		}
+		switch r.URL.Path {
+		case "/":
+			w.Write([]byte(`<html><head><title>Home</title></head><body>
+				<a href="/about">О нас</a>
+				<a href="http://www.example.com/contacts">Контакты</a>
+				<a href="http://blog.example.com/">Блог</a>
+			</body></html>`))
+		case "/about", "/contacts":
+			w.Write([]byte(`<html><head><title>Page</title></head><body><a href="/">Главная</a></body></html>`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	// Все хосты направляются на тестовый сервер
+	transport := NewTransport(nil)
+	transport.DialContext = func(ctx context.Context, network, _ string) (net.Conn, error) {
+		return (&net.Dialer{}).DialContext(ctx, network, server.Listener.Addr().String())
+	}
+	config := DefaultConfig()
+	config.RequestDelay = 0
+	config.IgnoreSitemaps = true
+	config.Transport = transport
+
+	crawler, err := NewSiteCrawler("http://example.com/", config, 20, 3)
+	if err != nil {
+		t.Fatalf("Failed to create crawler: %v", err)
+	}
+	result, err := crawler.CrawlSite(context.Background())
+	if err != nil {
+		t.Fatalf("CrawlSite failed: %v", err)
+	}
+
+	if page := result.Pages["http://example.com/"]; page == nil || page.StatusCode != http.StatusMovedPermanently {
+		t.Errorf("Expected start URL to be recorded as a redirect, got %+v", page)
+	}
+	for _, pageURL := range []string{"http://www.example.com/", "http://www.example.com/about", "http://www.example.com/contacts"} {
+		if page := result.Pages[pageURL]; page == nil || page.StatusCode != http.StatusOK {
+			t.Errorf("Expected %s to be crawled, got %+v", pageURL, page)
+		}
+	}
+	if _, ok := result.Pages["http://blog.example.com/"]; ok {
+		t.Error("Expected other subdomains to stay external")
+	}
+	if result.TotalPages != 4 {
+		t.Errorf("Expected 4 pages, got %d", result.TotalPages)
+	}
+}
+
+func TestSiteCrawler_CrawlSite_Politeness(t *testing.T) {
+	var (
+		mu        sync.Mutex
+		starts    []time.Time
+		active    int
+		maxActive int
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		starts = append(starts, time.Now())
+		active++
+		maxActive = max(maxActive, active)
+		mu.Unlock()
+		defer func() {
+			mu.Lock()
+			active--
+			mu.Unlock()
+		}()
+
+		time.Sleep(5 * time.Millisecond)
+		w.Write([]byte(`<html><head><title>Page</title></head><body>
+			<a href="/1">1</a><a href="/2">2</a><a href="/3">3</a><a href="/4">4</a><a href="/5">5</a><a href="/6">6</a>
+		</body></html>`))
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.RequestDelay = 0
+	config.RequestsPerSecond = 25
+	config.MaxConnsPerHost = 1
+	config.IgnoreRobotsTxt = true
+	config.IgnoreSitemaps = true
+
+	crawler, err := NewSiteCrawler(server.URL, config, 10, 3)
+	if err != nil {
+		t.Fatalf("Failed to create crawler: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := crawler.CrawlSite(ctx)
+	if err != nil {
+		t.Fatalf("CrawlSite failed: %v", err)
+	}
+	if result.TotalPages != 7 {
+		t.Fatalf("Expected 7 pages, got %d", result.TotalPages)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if maxActive != 1 {
+		t.Errorf("Expected at most 1 concurrent request, got %d", maxActive)
+	}
+	sort.Slice(starts, func(i, j int) bool { return starts[i].Before(starts[j]) })
+	for i := 1; i < len(starts); i++ {
+		// Допуск на неточность таймеров
+		if gap := starts[i].Sub(starts[i-1]); gap < 35*time.Millisecond {
+			t.Errorf("Request %d started %v after the previous one, want at least 40ms", i, gap)
+		}
+	}
+}
+
+func TestSiteCrawler_CrawlSite_Concurrency(t *testing.T) {
+	config := DefaultConfig()
+	config.Workers = 2
+	config.MaxConnsPerHost = 4
+	// Ограничение запросов к хосту сводится к количеству воркеров
+	clamped, err := NewSiteCrawler("https://example.com", config, 10, 3)
+	if err != nil {
+		t.Fatalf("Failed to create crawler: %v", err)
+	}
+	if got := clamped.crawler.limiter.maxConns; got != 2 {
+		t.Errorf("Expected max connections per host clamped to 2 workers, got %d", got)
+	}
+
+	server := newSyntheticSite(t, 30)
+	defer server.Close()
+
+	config = DefaultConfig()
+	config.RequestDelay = 0
+	config.IgnoreSitemaps = true
+	config.Workers = 1
+	config.MaxConnsPerHost = 1
+	config.MaxQueueSize = 2
+
+	crawler, err := NewSiteCrawler(server.URL, config, 100, -1)
+	if err != nil {
+		t.Fatalf("Failed to create crawler: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := crawler.CrawlSite(ctx)
+	if err != nil {
+		t.Fatalf("CrawlSite failed: %v", err)
+	}
+	if result.DroppedURLs == 0 {
+		t.Errorf("Expected links to be dropped with a queue of 2 URLs")
+	}
+	if result.TotalPages < 3 || result.TotalPages > 31 {
+		t.Errorf("Unexpected number of pages %d", result.TotalPages)
+	}
+}
@@ -0,0 +1,278 @@
+package crawler
+
+import (
+	"context"
+	"errors"
+	"image"
+	_ "image/gif"  // декодер GIF для определения размеров изображений
+	_ "image/jpeg" // декодер JPEG для определения размеров изображений
+	_ "image/png"  // декодер PNG для определения размеров изображений
+	"io"
+	"log"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+const (
+	// minSocialImageWidth и minSocialImageHeight — минимальные размеры изображения для превью в соцсетях
+	minSocialImageWidth  = 200
+	minSocialImageHeight = 200
+
+	// socialImageSniffLen — количество байт изображения, в которых ищутся его размеры
+	socialImageSniffLen = 256 << 10
+)
+
+// twitterCardTypes — допустимые значения twitter:card
+var twitterCardTypes = []string{"summary", "summary_large_image", "app", "player"}
+
+// TwitterCardProblem определяет проблему разметки Twitter Card
+type TwitterCardProblem string
+
+const (
+	// TwitterCardMissing — не указан twitter:card, карточка не отображается
+	TwitterCardMissing TwitterCardProblem = "missing"
+	// TwitterCardInvalid — неизвестный тип карточки
+	TwitterCardInvalid TwitterCardProblem = "invalid"
+	// TwitterCardMissingImage — у карточки summary_large_image нет twitter:image и og:image
+	TwitterCardMissingImage TwitterCardProblem = "missing-image"
+	// TwitterCardMissingPlayer — у карточки player нет twitter:player
+	TwitterCardMissingPlayer TwitterCardProblem = "missing-player"
+	// TwitterCardMissingAppID — у карточки app нет ни одного twitter:app:id:*
+	TwitterCardMissingAppID TwitterCardProblem = "missing-app-id"
+)
+
+// SocialReport содержит проверки разметки Open Graph и Twitter Card страниц сайта
+type SocialReport struct {
+	// MissingTitle, MissingImage и MissingURL содержат страницы без og:title, og:image и og:url
+	MissingTitle []string
+	MissingImage []string
+	MissingURL   []string
+	// URLMismatches содержит страницы, og:url которых не совпадает с canonical
+	URLMismatches []SocialURLMismatch
+	// Images содержит уникальные og:image и twitter:image по абсолютному URL
+	Images map[string]*SocialImage
+	// UnreachableImages содержит изображения с ответом, отличным от 200, или ошибкой запроса
+	// (только при Config.CheckImages)
+	UnreachableImages []*SocialImage
+	// SmallImages содержит изображения меньше 200×200 (только при Config.CheckImages)
+	SmallImages []*SocialImage
+	// TwitterCards содержит проблемы разметки Twitter Card
+	TwitterCards []TwitterCardIssue
+}
+
+// SocialURLMismatch представляет страницу, og:url которой не совпадает с canonical
+type SocialURLMismatch struct {
+	PageURL string
+	OGURL   string
+	// Canonical — canonical страницы или ее URL, если canonical не указан
+	Canonical string
+}
+
+// SocialImage представляет изображение для превью в соцсетях
+type SocialImage struct {
+	URL   string
+	Pages []string
+	// StatusCode, Width и Height заполняются при Config.CheckImages.
+	// Размеры определяются для PNG, JPEG и GIF.
+	StatusCode int
+	Width      int
+	Height     int
+	Error      error
+}
+
+// TwitterCardIssue представляет проблему разметки Twitter Card на странице
+type TwitterCardIssue struct {
+	PageURL string
+	Card    string
+	Problem TwitterCardProblem
+}
+
+// addSocialMeta сохраняет свойство og:* или twitter:* из тега meta.
+// Open Graph использует атрибут property, Twitter — name, но на практике встречаются оба.
+// Для повторяющихся свойств сохраняется первое значение.
+func addSocialMeta(result *CrawlerResult, n *html.Node) {
+	key := strings.ToLower(strings.TrimSpace(attrValue(n, "property")))
+	if key == "" {
+		key = strings.ToLower(strings.TrimSpace(attrValue(n, "name")))
+	}
+
+	var properties *map[string]string
+	switch {
+	case strings.HasPrefix(key, "og:"):
+		properties = &result.OpenGraph
+	case strings.HasPrefix(key, "twitter:"):
+		properties = &result.TwitterCard
+	default:
+		return
+	}
+	if *properties == nil {
+		*properties = make(map[string]string)
+	}
+	if _, ok := (*properties)[key]; !ok {
+		(*properties)[key] = strings.TrimSpace(attrValue(n, "content"))
+	}
+}
+
+// auditSocial проверяет разметку Open Graph и Twitter Card,
+// а при Config.CheckImages запрашивает изображения для превью
+func (sc *SiteCrawler) auditSocial(ctx context.Context, result *SiteCrawlerResult) {
+	report := &result.Social
+	report.Images = make(map[string]*SocialImage)
+
+	for pageURL, page := range result.Pages {
+		if !isContentPage(page) {
+			continue
+		}
+		og := page.OpenGraph
+		if og["og:title"] == "" {
+			report.MissingTitle = append(report.MissingTitle, pageURL)
+		}
+		if og["og:image"] == "" {
+			report.MissingImage = append(report.MissingImage, pageURL)
+		}
+
+		if og["og:url"] == "" {
+			report.MissingURL = append(report.MissingURL, pageURL)
+		} else {
+			expected := sc.canonicalURL(page)
+			if expected == "" {
+				expected = pageURL
+			}
+			if ogURL, err := sc.normalizer.Normalize(nil, og["og:url"]); err != nil || ogURL != expected {
+				report.URLMismatches = append(report.URLMismatches, SocialURLMismatch{
+					PageURL:   pageURL,
+					OGURL:     og["og:url"],
+					Canonical: expected,
+				})
+			}
+		}
+
+		base := documentBase(page)
+		reported := make(map[string]bool)
+		for _, src := range []string{og["og:image"], page.TwitterCard["twitter:image"]} {
+			imageURL := resolveImageURL(base, src)
+			if imageURL == "" || strings.HasPrefix(imageURL, "data:") || reported[imageURL] {
+				continue
+			}
+			reported[imageURL] = true
+			if _, ok := report.Images[imageURL]; !ok {
+				report.Images[imageURL] = &SocialImage{URL: imageURL}
+			}
+			report.Images[imageURL].Pages = append(report.Images[imageURL].Pages, pageURL)
+		}
+
+		if problem := twitterCardProblem(page); problem != "" {
+			report.TwitterCards = append(report.TwitterCards, TwitterCardIssue{
+				PageURL: pageURL,
+				Card:    page.TwitterCard["twitter:card"],
+				Problem: problem,
+			})
+		}
+	}
+	for _, img := range report.Images {
+		sort.Strings(img.Pages)
+	}
+
+	if sc.config.CheckImages && ctx.Err() == nil {
+		sc.checkSocialImages(ctx, report)
+	}
+
+	for _, urls := range [][]string{report.MissingTitle, report.MissingImage, report.MissingURL} {
+		sort.Strings(urls)
+	}
+	sort.Slice(report.URLMismatches, func(i, j int) bool {
+		return report.URLMismatches[i].PageURL < report.URLMismatches[j].PageURL
+	})
+	sort.Slice(report.TwitterCards, func(i, j int) bool {
+		return report.TwitterCards[i].PageURL < report.TwitterCards[j].PageURL
+	})
+	for _, images := range [][]*SocialImage{report.UnreachableImages, report.SmallImages} {
+		sort.Slice(images, func(i, j int) bool {
+			return images[i].URL < images[j].URL
+		})
+	}
+}
+
+// twitterCardProblem проверяет тип карточки и обязательные для него свойства.
+// Заголовок, описание и изображение карточки могут браться из Open Graph.
+func twitterCardProblem(page *CrawlerResult) TwitterCardProblem {
+	card := strings.ToLower(page.TwitterCard["twitter:card"])
+	switch {
+	case card == "":
+		return TwitterCardMissing
+	case !slices.Contains(twitterCardTypes, card):
+		return TwitterCardInvalid
+	}
+
+	switch card {
+	case "summary_large_image":
+		if page.TwitterCard["twitter:image"] == "" && page.OpenGraph["og:image"] == "" {
+			return TwitterCardMissingImage
+		}
+	case "player":
+		if page.TwitterCard["twitter:player"] == "" {
+			return TwitterCardMissingPlayer
+		}
+	case "app":
+		for key, value := range page.TwitterCard {
+			if strings.HasPrefix(key, "twitter:app:id:") && value != "" {
+				return ""
+			}
+		}
+		return TwitterCardMissingAppID
+	}
+	return ""
+}
+
+// checkSocialImages запрашивает изображения для превью и находит недоступные и слишком маленькие
+func (sc *SiteCrawler) checkSocialImages(ctx context.Context, report *SocialReport) {
+	log.Printf("Проверяем изображения для соцсетей: %d", len(report.Images))
+
+	images := make([]*SocialImage, 0, len(report.Images))
+	for _, img := range report.Images {
+		images = append(images, img)
+	}
+	runParallel(sc.numWorkers(), images, func(img *SocialImage) {
+		sc.crawler.checkSocialImage(ctx, img)
+	})
+
+	for _, img := range images {
+		switch {
+		case errors.Is(img.Error, context.Canceled) || errors.Is(img.Error, context.DeadlineExceeded):
+			// Проверка прервана, результат неизвестен
+		case img.Error != nil || img.StatusCode != http.StatusOK:
+			report.UnreachableImages = append(report.UnreachableImages, img)
+		case img.Width > 0 && (img.Width < minSocialImageWidth || img.Height < minSocialImageHeight):
+			report.SmallImages = append(report.SmallImages, img)
+		}
+	}
+}
+
+// checkSocialImage загружает начало изображения и определяет его статус и размеры
+func (c *HTTPCrawler) checkSocialImage(ctx context.Context, img *SocialImage) {
+	resp, err := c.fetchResource(ctx, http.MethodGet, img.URL)
+	if err != nil {
+		img.Error = err
+		return
+	}
+	defer resp.Body.Close()
+
+	img.StatusCode = resp.StatusCode
+	if resp.StatusCode != http.StatusOK {
+		return
+	}
+	reader, err := newBodyReader(resp)
+	if err != nil {
+		return
+	}
+	defer reader.Close()
+
+	if config, _, err := image.DecodeConfig(io.LimitReader(reader.reader, socialImageSniffLen)); err == nil {
+		img.Width = config.Width
+		img.Height = config.Height
+	}
+}
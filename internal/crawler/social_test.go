@@ -0,0 +1,132 @@
+package crawler
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestTwitterCardProblem(t *testing.T) {
+	tests := []struct {
+		name     string
+		twitter  map[string]string
+		og       map[string]string
+		expected TwitterCardProblem
+	}{
+		{"missing card", nil, nil, TwitterCardMissing},
+		{"unknown card", map[string]string{"twitter:card": "gallery"}, nil, TwitterCardInvalid},
+		{"summary", map[string]string{"twitter:card": "Summary"}, nil, ""},
+		{"large image from og", map[string]string{"twitter:card": "summary_large_image"}, map[string]string{"og:image": "/a.png"}, ""},
+		{"large image without image", map[string]string{"twitter:card": "summary_large_image"}, nil, TwitterCardMissingImage},
+		{"player without player", map[string]string{"twitter:card": "player"}, nil, TwitterCardMissingPlayer},
+		{"app with id", map[string]string{"twitter:card": "app", "twitter:app:id:iphone": "123"}, nil, ""},
+		{"app without id", map[string]string{"twitter:card": "app"}, nil, TwitterCardMissingAppID},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page := &CrawlerResult{TwitterCard: tt.twitter, OpenGraph: tt.og}
+			if got := twitterCardProblem(page); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestSiteCrawler_CrawlSite_Social(t *testing.T) {
+	encodePNG := func(width, height int) []byte {
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, width, height))); err != nil {
+			t.Fatalf("Failed to encode PNG: %v", err)
+		}
+		return buf.Bytes()
+	}
+	large, small := encodePNG(1200, 630), encodePNG(100, 100)
+
+	var serverURL string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			w.Write([]byte(`<html><head>
+				<meta property="og:title" content="Главная">
+				<meta property="og:url" content="` + serverURL + `/">
+				<meta property="og:image" content="/large.png">
+				<meta name="twitter:card" content="summary_large_image">
+				</head><body><a href="/about">О нас</a><a href="/blog">Блог</a></body></html>`))
+		case "/about":
+			w.Write([]byte(`<html><head>
+				<link rel="canonical" href="/about">
+				<meta property="og:title" content="О нас">
+				<meta property="og:url" content="` + serverURL + `/about-us">
+				<meta property="og:image" content="/small.png">
+				<meta name="twitter:image" content="/missing.png">
+				</head><body></body></html>`))
+		case "/blog":
+			w.Write([]byte(`<html><head><meta name="twitter:card" content="player"></head><body></body></html>`))
+		case "/large.png":
+			w.Write(large)
+		case "/small.png":
+			w.Write(small)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	serverURL = server.URL
+
+	config := DefaultConfig()
+	config.RequestDelay = 0
+	config.IgnoreSitemaps = true
+	config.CheckImages = true
+
+	crawler, err := NewSiteCrawler(server.URL, config, 10, 3)
+	if err != nil {
+		t.Fatalf("Failed to create crawler: %v", err)
+	}
+	result, err := crawler.CrawlSite(context.Background())
+	if err != nil {
+		t.Fatalf("CrawlSite failed: %v", err)
+	}
+
+	if got := result.Pages[server.URL+"/"].OpenGraph["og:title"]; got != "Главная" {
+		t.Errorf("Expected og:title to be parsed, got %q", got)
+	}
+
+	report := result.Social
+	if !reflect.DeepEqual(report.MissingTitle, []string{server.URL + "/blog"}) ||
+		!reflect.DeepEqual(report.MissingImage, []string{server.URL + "/blog"}) ||
+		!reflect.DeepEqual(report.MissingURL, []string{server.URL + "/blog"}) {
+		t.Errorf("Unexpected missing tags: %v, %v, %v", report.MissingTitle, report.MissingImage, report.MissingURL)
+	}
+
+	expectedMismatches := []SocialURLMismatch{{PageURL: server.URL + "/about", OGURL: server.URL + "/about-us", Canonical: server.URL + "/about"}}
+	if !reflect.DeepEqual(report.URLMismatches, expectedMismatches) {
+		t.Errorf("Expected mismatches %+v, got %+v", expectedMismatches, report.URLMismatches)
+	}
+
+	if len(report.Images) != 3 {
+		t.Errorf("Expected 3 social images, got %d", len(report.Images))
+	}
+	if img := report.Images[server.URL+"/large.png"]; img == nil || img.Width != 1200 || img.Height != 630 {
+		t.Errorf("Expected large image dimensions to be detected, got %+v", img)
+	}
+	if len(report.SmallImages) != 1 || report.SmallImages[0].URL != server.URL+"/small.png" {
+		t.Errorf("Expected /small.png to be reported as small, got %+v", report.SmallImages)
+	}
+	if len(report.UnreachableImages) != 1 || report.UnreachableImages[0].StatusCode != http.StatusNotFound {
+		t.Errorf("Expected /missing.png to be unreachable, got %+v", report.UnreachableImages)
+	}
+
+	expectedCards := []TwitterCardIssue{
+		{PageURL: server.URL + "/about", Problem: TwitterCardMissing},
+		{PageURL: server.URL + "/blog", Card: "player", Problem: TwitterCardMissingPlayer},
+	}
+	if !reflect.DeepEqual(report.TwitterCards, expectedCards) {
+		t.Errorf("Expected Twitter Card issues %+v, got %+v", expectedCards, report.TwitterCards)
+	}
+}
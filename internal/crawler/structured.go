@@ -0,0 +1,449 @@
+package crawler
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// StructuredDataFormat определяет синтаксис разметки структурированных данных
+type StructuredDataFormat string
+
+const (
+	// FormatJSONLD — блок <script type="application/ld+json">
+	FormatJSONLD StructuredDataFormat = "json-ld"
+	// FormatMicrodata — атрибуты itemscope, itemtype и itemprop
+	FormatMicrodata StructuredDataFormat = "microdata"
+	// FormatRDFa — атрибуты typeof и property
+	FormatRDFa StructuredDataFormat = "rdfa"
+)
+
+// StructuredItem представляет элемент структурированных данных
+type StructuredItem struct {
+	Format StructuredDataFormat
+	// Types содержит типы schema.org без префикса словаря (Product, Article)
+	Types []string
+	// Properties содержит значения свойств по имени свойства
+	Properties map[string][]StructuredValue
+}
+
+// StructuredValue представляет значение свойства: текст или вложенный элемент
+type StructuredValue struct {
+	Text string
+	Item *StructuredItem
+}
+
+// schemaRule определяет обязательные и рекомендуемые свойства типа schema.org.
+// Свойство вложенного элемента указывается через точку (item.name).
+type schemaRule struct {
+	required []string
+	// requiredOneOf — группы свойств, из которых должно быть указано хотя бы одно
+	requiredOneOf [][]string
+	recommended   []string
+}
+
+// schemaRules содержит правила проверки распространенных типов schema.org
+// по требованиям Google к расширенным результатам
+var schemaRules = map[string]schemaRule{
+	"Product": {
+		required:      []string{"name"},
+		requiredOneOf: [][]string{{"offers", "review", "aggregateRating"}},
+		recommended:   []string{"image", "description", "sku", "brand"},
+	},
+	"Offer": {
+		requiredOneOf: [][]string{{"price", "priceSpecification"}},
+		recommended:   []string{"priceCurrency", "availability"},
+	},
+	"Article": {
+		required:    []string{"headline"},
+		recommended: []string{"image", "datePublished", "dateModified", "author"},
+	},
+	"BreadcrumbList": {
+		required: []string{"itemListElement"},
+	},
+	"ListItem": {
+		required:      []string{"position"},
+		requiredOneOf: [][]string{{"name", "item.name"}},
+		recommended:   []string{"item"},
+	},
+	"FAQPage": {
+		required: []string{"mainEntity"},
+	},
+	"Question": {
+		required: []string{"name", "acceptedAnswer"},
+	},
+	"Answer": {
+		required: []string{"text"},
+	},
+	"Organization": {
+		required:    []string{"name"},
+		recommended: []string{"url", "logo", "sameAs"},
+	},
+	"LocalBusiness": {
+		required:    []string{"name", "address"},
+		recommended: []string{"telephone", "url", "image", "openingHoursSpecification", "geo", "priceRange"},
+	},
+}
+
+// schemaAliases сводит подтипы schema.org к типам, для которых есть правила
+var schemaAliases = map[string]string{
+	"NewsArticle":   "Article",
+	"BlogPosting":   "Article",
+	"Store":         "LocalBusiness",
+	"Restaurant":    "LocalBusiness",
+	"AutoDealer":    "LocalBusiness",
+	"MedicalClinic": "LocalBusiness",
+}
+
+// schemaPrefixes — префиксы словаря schema.org, отбрасываемые в именах типов и свойств
+var schemaPrefixes = []string{"https://schema.org/", "http://schema.org/", "schema:"}
+
+// StructuredDataIssue представляет отсутствующее свойство элемента структурированных данных
+type StructuredDataIssue struct {
+	PageURL string
+	Format  StructuredDataFormat
+	// Type — тип элемента, в котором не хватает свойства
+	Type string
+	// Property — путь к свойству (например, offers.price). Для групп,
+	// из которых достаточно одного свойства, варианты перечислены через "|".
+	Property string
+}
+
+// StructuredDataReport содержит охват и ошибки структурированных данных сайта
+type StructuredDataReport struct {
+	// ContentPages — количество проверенных HTML-страниц
+	ContentPages int
+	// PagesWithData — количество страниц со структурированными данными
+	PagesWithData int
+	// Types содержит количество страниц с каждым типом верхнего уровня
+	Types map[string]int
+	// Formats содержит количество страниц с каждым синтаксисом разметки
+	Formats map[StructuredDataFormat]int
+	// Errors содержит отсутствующие обязательные свойства
+	Errors []StructuredDataIssue
+	// Warnings содержит отсутствующие рекомендуемые свойства
+	Warnings []StructuredDataIssue
+	// ParseErrors содержит ошибки разбора JSON-LD по страницам
+	ParseErrors map[string][]string
+}
+
+// ValidateStructuredItem проверяет элемент и вложенные элементы по правилам распространенных
+// типов schema.org. Возвращает отсутствующие обязательные и рекомендуемые свойства.
+func ValidateStructuredItem(item *StructuredItem) (errs, warnings []StructuredDataIssue) {
+	validateItem(item, "", &errs, &warnings)
+	return errs, warnings
+}
+
+// validateItem проверяет элемент, path — путь к элементу от элемента верхнего уровня
+func validateItem(item *StructuredItem, path string, errs, warnings *[]StructuredDataIssue) {
+	for _, itemType := range item.Types {
+		rule, ok := schemaRules[itemType]
+		if !ok {
+			rule, ok = schemaRules[schemaAliases[itemType]]
+		}
+		if !ok {
+			continue
+		}
+		issue := func(property string) StructuredDataIssue {
+			return StructuredDataIssue{Format: item.Format, Type: itemType, Property: path + property}
+		}
+		for _, property := range rule.required {
+			if !item.has(property) {
+				*errs = append(*errs, issue(property))
+			}
+		}
+		for _, group := range rule.requiredOneOf {
+			found := false
+			for _, property := range group {
+				found = found || item.has(property)
+			}
+			if !found {
+				*errs = append(*errs, issue(strings.Join(group, "|")))
+			}
+		}
+		for _, property := range rule.recommended {
+			if !item.has(property) {
+				*warnings = append(*warnings, issue(property))
+			}
+		}
+	}
+
+	names := make([]string, 0, len(item.Properties))
+	for name := range item.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, value := range item.Properties[name] {
+			if value.Item != nil {
+				validateItem(value.Item, path+name+".", errs, warnings)
+			}
+		}
+	}
+}
+
+// has проверяет, что свойство указано и имеет непустое значение.
+// Для пути через точку (item.name) проверяется свойство вложенного элемента.
+func (item *StructuredItem) has(property string) bool {
+	property, nested, isPath := strings.Cut(property, ".")
+	for _, value := range item.Properties[property] {
+		if isPath {
+			if value.Item != nil && value.Item.has(nested) {
+				return true
+			}
+			continue
+		}
+		if value.Item != nil || strings.TrimSpace(value.Text) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// addProperty добавляет значение свойства
+func (item *StructuredItem) addProperty(name string, value StructuredValue) {
+	if item.Properties == nil {
+		item.Properties = make(map[string][]StructuredValue)
+	}
+	item.Properties[name] = append(item.Properties[name], value)
+}
+
+// schemaName отбрасывает префикс словаря schema.org в имени типа или свойства
+func schemaName(name string) string {
+	for _, prefix := range schemaPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return name[len(prefix):]
+		}
+	}
+	return name
+}
+
+// parseJSONLD разбирает содержимое блока JSON-LD. Блок может содержать объект,
+// массив объектов или объект с @graph.
+func parseJSONLD(data string) ([]StructuredItem, error) {
+	var raw any
+	if err := json.Unmarshal([]byte(data), &raw); err != nil {
+		return nil, err
+	}
+
+	var items []StructuredItem
+	var add func(v any)
+	add = func(v any) {
+		switch v := v.(type) {
+		case []any:
+			for _, element := range v {
+				add(element)
+			}
+		case map[string]any:
+			if graph, ok := v["@graph"]; ok {
+				add(graph)
+				return
+			}
+			items = append(items, *jsonLDItem(v))
+		}
+	}
+	add(raw)
+	return items, nil
+}
+
+// jsonLDItem преобразует объект JSON-LD в элемент структурированных данных
+func jsonLDItem(object map[string]any) *StructuredItem {
+	item := &StructuredItem{Format: FormatJSONLD}
+	switch types := object["@type"].(type) {
+	case string:
+		item.Types = append(item.Types, schemaName(types))
+	case []any:
+		for _, t := range types {
+			if s, ok := t.(string); ok {
+				item.Types = append(item.Types, schemaName(s))
+			}
+		}
+	}
+
+	for key, value := range object {
+		if strings.HasPrefix(key, "@") {
+			continue
+		}
+		for _, v := range jsonLDValues(value) {
+			item.addProperty(schemaName(key), v)
+		}
+	}
+	return item
+}
+
+// jsonLDValues преобразует значение свойства JSON-LD в список значений
+func jsonLDValues(value any) []StructuredValue {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []any:
+		var values []StructuredValue
+		for _, element := range v {
+			values = append(values, jsonLDValues(element)...)
+		}
+		return values
+	case map[string]any:
+		// Объект только со значением ({"@value": ...}) или ссылкой ({"@id": ...}) считается текстом
+		if _, typed := v["@type"]; !typed && len(v) == 1 {
+			for _, key := range []string{"@value", "@id"} {
+				if inner, ok := v[key]; ok {
+					return jsonLDValues(inner)
+				}
+			}
+		}
+		return []StructuredValue{{Item: jsonLDItem(v)}}
+	case string:
+		return []StructuredValue{{Text: v}}
+	case float64:
+		return []StructuredValue{{Text: strconv.FormatFloat(v, 'f', -1, 64)}}
+	default:
+		return []StructuredValue{{Text: fmt.Sprint(v)}}
+	}
+}
+
+// markupSyntax описывает атрибуты разметки в HTML: Microdata или RDFa
+type markupSyntax struct {
+	format StructuredDataFormat
+	// scope — атрибут, начинающий элемент
+	scope string
+	// types — атрибут с типами элемента
+	types string
+	// property — атрибут с именами свойств
+	property string
+}
+
+var (
+	microdataSyntax = markupSyntax{format: FormatMicrodata, scope: "itemscope", types: "itemtype", property: "itemprop"}
+	rdfaSyntax      = markupSyntax{format: FormatRDFa, scope: "typeof", types: "typeof", property: "property"}
+)
+
+// isTopLevel проверяет, что элемент HTML начинает элемент верхнего уровня
+func (s markupSyntax) isTopLevel(n *html.Node) bool {
+	return hasAttr(n, s.scope) && !hasAttr(n, s.property)
+}
+
+// parseItem разбирает элемент разметки, начинающийся с узла n
+func (s markupSyntax) parseItem(n *html.Node) *StructuredItem {
+	item := &StructuredItem{Format: s.format}
+	for _, t := range strings.Fields(attrValue(n, s.types)) {
+		item.Types = append(item.Types, schemaName(t))
+	}
+	s.collectProperties(item, n)
+	return item
+}
+
+// collectProperties добавляет в элемент свойства из потомков узла n
+func (s markupSyntax) collectProperties(item *StructuredItem, n *html.Node) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode {
+			continue
+		}
+		names := strings.Fields(attrValue(c, s.property))
+		nested := hasAttr(c, s.scope)
+		if len(names) > 0 {
+			var value StructuredValue
+			if nested {
+				value.Item = s.parseItem(c)
+			} else {
+				value.Text = markupValue(c)
+			}
+			for _, name := range names {
+				item.addProperty(schemaName(name), value)
+			}
+		}
+		// Свойства вложенных и независимых элементов к этому элементу не относятся
+		if !nested {
+			s.collectProperties(item, c)
+		}
+	}
+}
+
+// markupValue возвращает значение свойства Microdata или RDFa из элемента
+func markupValue(n *html.Node) string {
+	if hasAttr(n, "content") {
+		return attrValue(n, "content")
+	}
+	switch n.Data {
+	case "a", "link", "area":
+		return attrValue(n, "href")
+	case "img", "audio", "video", "source", "iframe", "embed":
+		return attrValue(n, "src")
+	case "object":
+		return attrValue(n, "data")
+	case "time":
+		if hasAttr(n, "datetime") {
+			return attrValue(n, "datetime")
+		}
+	case "data", "meter":
+		return attrValue(n, "value")
+	}
+	return strings.Join(strings.Fields(extractText(n)), " ")
+}
+
+// hasAttr проверяет наличие атрибута у элемента
+func hasAttr(n *html.Node, key string) bool {
+	for _, attr := range n.Attr {
+		if attr.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// auditStructuredData вычисляет охват структурированных данных и проверяет элементы
+func (sc *SiteCrawler) auditStructuredData(result *SiteCrawlerResult) {
+	report := &result.StructuredData
+	report.Types = make(map[string]int)
+	report.Formats = make(map[StructuredDataFormat]int)
+	report.ParseErrors = make(map[string][]string)
+
+	for pageURL, page := range result.Pages {
+		if !isContentPage(page) {
+			continue
+		}
+		report.ContentPages++
+		if len(page.StructuredDataErrors) > 0 {
+			report.ParseErrors[pageURL] = page.StructuredDataErrors
+		}
+		if len(page.StructuredData) == 0 {
+			continue
+		}
+		report.PagesWithData++
+
+		types := make(map[string]bool)
+		formats := make(map[StructuredDataFormat]bool)
+		for i := range page.StructuredData {
+			item := &page.StructuredData[i]
+			formats[item.Format] = true
+			for _, t := range item.Types {
+				types[t] = true
+			}
+
+			errs, warnings := ValidateStructuredItem(item)
+			for _, issue := range errs {
+				issue.PageURL = pageURL
+				report.Errors = append(report.Errors, issue)
+			}
+			for _, issue := range warnings {
+				issue.PageURL = pageURL
+				report.Warnings = append(report.Warnings, issue)
+			}
+		}
+		for t := range types {
+			report.Types[t]++
+		}
+		for format := range formats {
+			report.Formats[format]++
+		}
+	}
+
+	for _, issues := range [][]StructuredDataIssue{report.Errors, report.Warnings} {
+		sort.SliceStable(issues, func(i, j int) bool {
+			return issues[i].PageURL < issues[j].PageURL
+		})
+	}
+}
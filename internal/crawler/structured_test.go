@@ -0,0 +1,184 @@
+package crawler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+// productPage содержит один и тот же товар в JSON-LD, Microdata и RDFa
+const productPage = `<html><head>
+<script type="application/ld+json">
+{
+	"@context": "https://schema.org",
+	"@graph": [
+		{"@type": "Product", "name": "Чайник", "offers": {"@type": "Offer", "price": 1990, "priceCurrency": "RUB"}},
+		{"@type": "BreadcrumbList", "itemListElement": [
+			{"@type": "ListItem", "position": 1, "name": "Главная", "item": "https://shop.ru/"},
+			{"@type": "ListItem", "position": 2, "item": "https://shop.ru/kettles"}
+		]}
+	]
+}
+</script>
+<script type="application/ld+json">{"@type": "Organization", </script>
+</head><body>
+<div itemscope itemtype="https://schema.org/Product">
+	<h1 itemprop="name">Чайник</h1>
+	<img itemprop="image" src="/kettle.jpg">
+	<div itemprop="offers" itemscope itemtype="https://schema.org/Offer">
+		<meta itemprop="priceCurrency" content="RUB"><span itemprop="price">1990</span>
+	</div>
+	<div itemscope itemtype="https://schema.org/Organization"><span itemprop="name">Магазин</span></div>
+</div>
+<div vocab="https://schema.org/" typeof="Article">
+	<h2 property="headline">Как выбрать чайник</h2>
+	<time property="datePublished" datetime="2024-05-01">1 мая</time>
+</div>
+</body></html>`
+
+func TestHTTPCrawler_ParseDocument_StructuredData(t *testing.T) {
+	doc, err := html.Parse(strings.NewReader(productPage))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+	result := &CrawlerResult{URL: "https://shop.ru/kettle", FinalURL: "https://shop.ru/kettle"}
+	NewHTTPCrawler(DefaultConfig()).parseDocument(result, doc)
+
+	var formats []StructuredDataFormat
+	var types []string
+	for _, item := range result.StructuredData {
+		formats = append(formats, item.Format)
+		types = append(types, strings.Join(item.Types, ","))
+	}
+	expectedFormats := []StructuredDataFormat{FormatJSONLD, FormatJSONLD, FormatMicrodata, FormatMicrodata, FormatRDFa}
+	expectedTypes := []string{"Product", "BreadcrumbList", "Product", "Organization", "Article"}
+	if !reflect.DeepEqual(formats, expectedFormats) || !reflect.DeepEqual(types, expectedTypes) {
+		t.Fatalf("Unexpected items: formats %v, types %v", formats, types)
+	}
+	if len(result.StructuredDataErrors) != 1 {
+		t.Errorf("Expected one JSON-LD parse error, got %v", result.StructuredDataErrors)
+	}
+
+	product := result.StructuredData[2]
+	offer := product.Properties["offers"][0].Item
+	if offer == nil || offer.Properties["price"][0].Text != "1990" || offer.Properties["priceCurrency"][0].Text != "RUB" {
+		t.Errorf("Unexpected microdata offer: %+v", offer)
+	}
+	if product.Properties["image"][0].Text != "/kettle.jpg" {
+		t.Errorf("Expected image from src, got %+v", product.Properties["image"])
+	}
+	// Вложенный элемент без itemprop является отдельным элементом, а не свойством товара
+	if len(product.Properties) != 3 {
+		t.Errorf("Expected 3 product properties, got %v", product.Properties)
+	}
+	if article := result.StructuredData[4]; article.Properties["datePublished"][0].Text != "2024-05-01" {
+		t.Errorf("Expected datetime value, got %+v", article.Properties)
+	}
+}
+
+func TestValidateStructuredItem(t *testing.T) {
+	items, err := parseJSONLD(`[
+		{"@type": "Product", "name": "Чайник", "offers": {"@type": "Offer", "priceCurrency": "RUB"}},
+		{"@type": "Product", "name": "", "image": "a.jpg", "description": "d", "sku": "1", "brand": "b", "review": {"@type": "Review"}},
+		{"@type": "FAQPage", "mainEntity": [{"@type": "Question", "name": "Доставка?", "acceptedAnswer": {"@type": "Answer"}}]},
+		{"@type": "NewsArticle", "headline": "Новости", "image": "a.jpg", "datePublished": "2024", "dateModified": "2024", "author": "Иван"},
+		{"@type": "Offer", "priceSpecification": {"@type": "UnitPriceSpecification", "price": 1990}, "priceCurrency": "RUB", "availability": "InStock"},
+		{"@type": "BreadcrumbList", "itemListElement": [
+			{"@type": "ListItem", "position": 1, "item": {"@id": "https://shop.ru/", "name": "Главная"}},
+			{"@type": "ListItem", "position": 2, "item": "https://shop.ru/kettles"}
+		]}
+	]`)
+	if err != nil {
+		t.Fatalf("parseJSONLD failed: %v", err)
+	}
+
+	tests := []struct {
+		name             string
+		expectedErrors   []string
+		expectedWarnings []string
+	}{
+		{"product without price", []string{"Offer offers.price|priceSpecification"}, []string{
+			"Product image", "Product description", "Product sku", "Product brand", "Offer offers.availability",
+		}},
+		{"product with empty name", []string{"Product name"}, nil},
+		{"faq without answer text", []string{"Answer mainEntity.acceptedAnswer.text"}, nil},
+		{"valid news article", nil, nil},
+		{"offer with price specification", nil, nil},
+		{"breadcrumb with item name", []string{"ListItem itemListElement.name|item.name"}, nil},
+	}
+
+	describe := func(issues []StructuredDataIssue) []string {
+		var result []string
+		for _, issue := range issues {
+			result = append(result, issue.Type+" "+issue.Property)
+		}
+		return result
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs, warnings := ValidateStructuredItem(&items[i])
+			if got := describe(errs); !reflect.DeepEqual(got, tt.expectedErrors) {
+				t.Errorf("Expected errors %v, got %v", tt.expectedErrors, got)
+			}
+			if got := describe(warnings); !reflect.DeepEqual(got, tt.expectedWarnings) {
+				t.Errorf("Expected warnings %v, got %v", tt.expectedWarnings, got)
+			}
+		})
+	}
+}
+
+func TestSiteCrawler_CrawlSite_StructuredData(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			w.Write([]byte(`<html><body><a href="/kettle">Чайник</a></body></html>`))
+		case "/kettle":
+			w.Write([]byte(productPage))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.RequestDelay = 0
+	config.IgnoreSitemaps = true
+
+	crawler, err := NewSiteCrawler(server.URL, config, 10, 3)
+	if err != nil {
+		t.Fatalf("Failed to create crawler: %v", err)
+	}
+	result, err := crawler.CrawlSite(context.Background())
+	if err != nil {
+		t.Fatalf("CrawlSite failed: %v", err)
+	}
+
+	report := result.StructuredData
+	if report.ContentPages != 2 || report.PagesWithData != 1 {
+		t.Errorf("Expected structured data on 1 of 2 pages, got %d of %d", report.PagesWithData, report.ContentPages)
+	}
+	expectedTypes := map[string]int{"Product": 1, "BreadcrumbList": 1, "Organization": 1, "Article": 1}
+	if !reflect.DeepEqual(report.Types, expectedTypes) {
+		t.Errorf("Expected types %v, got %v", expectedTypes, report.Types)
+	}
+	if report.Formats[FormatJSONLD] != 1 || report.Formats[FormatMicrodata] != 1 || report.Formats[FormatRDFa] != 1 {
+		t.Errorf("Unexpected formats: %v", report.Formats)
+	}
+
+	var errs []string
+	for _, issue := range report.Errors {
+		errs = append(errs, string(issue.Format)+" "+issue.Type+" "+issue.Property)
+	}
+	expectedErrors := []string{"json-ld ListItem itemListElement.name|item.name"}
+	if !reflect.DeepEqual(errs, expectedErrors) {
+		t.Errorf("Expected errors %v, got %v", expectedErrors, errs)
+	}
+	if len(report.ParseErrors[server.URL+"/kettle"]) != 1 {
+		t.Errorf("Expected JSON-LD parse error to be reported, got %v", report.ParseErrors)
+	}
+}
@@ -0,0 +1,66 @@
+package crawler
+
+import (
+	"net"
+	"net/http"
+	"time"
+)
+
+const (
+	// defaultDialTimeout — таймаут установки TCP-соединения по умолчанию
+	defaultDialTimeout = 10 * time.Second
+
+	// defaultTLSHandshakeTimeout — таймаут TLS-рукопожатия по умолчанию
+	defaultTLSHandshakeTimeout = 10 * time.Second
+
+	// defaultResponseHeaderTimeout — таймаут ожидания заголовков ответа по умолчанию
+	defaultResponseHeaderTimeout = 20 * time.Second
+
+	// defaultIdleConnTimeout — время жизни неиспользуемого keep-alive соединения
+	defaultIdleConnTimeout = 90 * time.Second
+)
+
+// NewTransport создает HTTP-транспорт, настроенный для краулинга:
+// keep-alive соединения, пул простаивающих соединений по числу воркеров,
+// HTTP/2 и таймауты на каждом этапе запроса.
+// Сжатие отключено, так как ответы распаковываются краулером для подсчета размеров.
+func NewTransport(config *Config) *http.Transport {
+	if config == nil {
+		config = DefaultConfig()
+	}
+
+	workers := config.Workers
+	if workers == 0 {
+		workers = defaultWorkers
+	}
+	connsPerHost := config.MaxConnsPerHost
+	if connsPerHost == 0 {
+		connsPerHost = defaultMaxConnsPerHost
+	}
+
+	dialer := &net.Dialer{
+		Timeout:   durationOrDefault(config.DialTimeout, defaultDialTimeout),
+		KeepAlive: 30 * time.Second,
+	}
+
+	return &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          max(100, workers*2),
+		MaxIdleConnsPerHost:   max(connsPerHost, workers),
+		IdleConnTimeout:       defaultIdleConnTimeout,
+		TLSHandshakeTimeout:   durationOrDefault(config.TLSHandshakeTimeout, defaultTLSHandshakeTimeout),
+		ResponseHeaderTimeout: durationOrDefault(config.ResponseHeaderTimeout, defaultResponseHeaderTimeout),
+		ExpectContinueTimeout: time.Second,
+		DisableCompression:    true,
+	}
+}
+
+// durationOrDefault возвращает d или значение по умолчанию, если d не задано
+func durationOrDefault(d, def time.Duration) time.Duration {
+	if d > 0 {
+		return d
+	}
+	return def
+}
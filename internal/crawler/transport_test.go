@@ -0,0 +1,111 @@
+package crawler
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingTransport подсчитывает запросы, прошедшие через транспорт
+type countingTransport struct {
+	next     http.RoundTripper
+	requests atomic.Int32
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.requests.Add(1)
+	return t.next.RoundTrip(req)
+}
+
+// newConnCountingServer запускает сервер, который подсчитывает новые TCP-соединения
+func newConnCountingServer(t testing.TB, handler http.Handler) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var conns atomic.Int32
+	server := httptest.NewUnstartedServer(handler)
+	server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			conns.Add(1)
+		}
+	}
+	server.Start()
+	return server, &conns
+}
+
+func TestSiteCrawler_CrawlSite_SharedTransport(t *testing.T) {
+	server, conns := newConnCountingServer(t, syntheticSiteHandler(20))
+	defer server.Close()
+
+	transport := &countingTransport{next: NewTransport(nil)}
+	config := DefaultConfig()
+	config.RequestDelay = 0
+	config.Workers = 4
+	config.MaxConnsPerHost = 2
+	config.Transport = transport
+
+	crawler, err := NewSiteCrawler(server.URL, config, 100, -1)
+	if err != nil {
+		t.Fatalf("Failed to create crawler: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := crawler.CrawlSite(ctx)
+	if err != nil {
+		t.Fatalf("CrawlSite failed: %v", err)
+	}
+	if result.TotalPages != 21 {
+		t.Fatalf("Expected 21 pages, got %d", result.TotalPages)
+	}
+
+	// Страницы, robots.txt и sitemap загружаются через переданный транспорт
+	if requests := transport.requests.Load(); requests < 23 {
+		t.Errorf("Expected all requests to go through the injected transport, got %d", requests)
+	}
+	// Keep-alive: соединений не больше, чем параллельных запросов к хосту
+	if n := conns.Load(); n > 4 {
+		t.Errorf("Expected connections to be reused, got %d new connections", n)
+	}
+}
+
+func BenchmarkHTTPCrawler_CrawlPage(b *testing.B) {
+	config := DefaultConfig()
+	config.RequestDelay = 0
+	config.IgnoreRobotsTxt = true
+	config.DisableAdaptiveThrottling = true
+
+	b.Run("SharedTransport", func(b *testing.B) {
+		server, conns := newConnCountingServer(b, syntheticSiteHandler(20))
+		defer server.Close()
+
+		crawler := NewHTTPCrawler(config)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, err := crawler.CrawlPage(context.Background(), server.URL+"/"); err != nil {
+				b.Fatal(err)
+			}
+		}
+		b.ReportMetric(float64(conns.Load())/float64(b.N), "conns/op")
+	})
+
+	// Поведение до общего транспорта: новый клиент на каждую страницу
+	b.Run("CrawlerPerPage", func(b *testing.B) {
+		server, conns := newConnCountingServer(b, syntheticSiteHandler(20))
+		defer server.Close()
+
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			crawler := NewHTTPCrawler(config)
+			if _, err := crawler.CrawlPage(context.Background(), server.URL+"/"); err != nil {
+				b.Fatal(err)
+			}
+			crawler.client.CloseIdleConnections()
+		}
+		b.ReportMetric(float64(conns.Load())/float64(b.N), "conns/op")
+	})
+}
@@ -0,0 +1,119 @@
+package robots
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+// Cache загружает и кэширует robots.txt для каждого хоста
+type Cache struct {
+	client    *http.Client
+	userAgent string
+
+	mu      sync.Mutex
+	entries map[string]*cacheEntry
+}
+
+// cacheEntry хранит robots.txt одного хоста.
+// Канал ready закрывается, когда загрузка завершена.
+type cacheEntry struct {
+	ready  chan struct{}
+	robots *Robots
+	// err — ошибка прерванной загрузки, такая запись удаляется из кэша
+	err error
+}
+
+// NewCache создает новый кэш robots.txt
+func NewCache(client *http.Client, userAgent string) *Cache {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &Cache{
+		client:    client,
+		userAgent: userAgent,
+		entries:   make(map[string]*cacheEntry),
+	}
+}
+
+// Get возвращает robots.txt для хоста указанного URL.
+// Параллельные запросы к одному хосту ждут единственной загрузки.
+func (c *Cache) Get(ctx context.Context, u *url.URL) (*Robots, error) {
+	key := u.Scheme + "://" + u.Host
+
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	if !ok {
+		entry = &cacheEntry{ready: make(chan struct{})}
+		c.entries[key] = entry
+	}
+	c.mu.Unlock()
+
+	if !ok {
+		robots := c.fetch(ctx, key+"/robots.txt")
+		if err := ctx.Err(); err != nil {
+			// Результат прерванной загрузки не кэшируем: ожидающие получают ошибку,
+			// а следующий запрос загружает robots.txt заново
+			c.mu.Lock()
+			delete(c.entries, key)
+			c.mu.Unlock()
+			entry.err = err
+		} else {
+			entry.robots = robots
+		}
+		close(entry.ready)
+	}
+
+	select {
+	case <-entry.ready:
+		return entry.robots, entry.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Test проверяет, разрешен ли URL для user-agent'а кэша
+func (c *Cache) Test(ctx context.Context, rawURL string) (bool, *Rule, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false, nil, err
+	}
+
+	robots, err := c.Get(ctx, u)
+	if err != nil {
+		return false, nil, err
+	}
+
+	allowed, rule := robots.Test(c.userAgent, u.RequestURI())
+	return allowed, rule, nil
+}
+
+// fetch загружает robots.txt.
+// Ответы 4xx означают отсутствие ограничений, ошибки сети и 5xx — полный запрет.
+func (c *Cache) fetch(ctx context.Context, robotsURL string) *Robots {
+	req, err := http.NewRequestWithContext(ctx, "GET", robotsURL, nil)
+	if err != nil {
+		return DisallowAll()
+	}
+	req.Header.Set("User-Agent", c.userAgent)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return DisallowAll()
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		robots, err := Parse(resp.Body)
+		if err != nil {
+			return AllowAll()
+		}
+		return robots
+	case resp.StatusCode >= 400 && resp.StatusCode < 500:
+		return AllowAll()
+	default:
+		return DisallowAll()
+	}
+}
@@ -0,0 +1,264 @@
+package robots
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxFileSize ограничивает размер обрабатываемого robots.txt (как у Google — 500 КиБ)
+const maxFileSize = 500 * 1024
+
+// Rule представляет одно правило Allow/Disallow
+type Rule struct {
+	Allow bool
+	Path  string
+}
+
+// String возвращает правило в том виде, в котором оно записано в robots.txt
+func (r Rule) String() string {
+	if r.Allow {
+		return "Allow: " + r.Path
+	}
+	return "Disallow: " + r.Path
+}
+
+// Group представляет группу правил для набора user-agent'ов
+type Group struct {
+	UserAgents []string
+	Rules      []Rule
+	CrawlDelay time.Duration
+}
+
+// Robots представляет разобранный файл robots.txt
+type Robots struct {
+	Groups   []Group
+	Sitemaps []string
+}
+
+// AllowAll возвращает robots.txt без ограничений
+func AllowAll() *Robots {
+	return &Robots{}
+}
+
+// DisallowAll возвращает robots.txt, запрещающий обход всего сайта
+func DisallowAll() *Robots {
+	return &Robots{
+		Groups: []Group{{
+			UserAgents: []string{"*"},
+			Rules:      []Rule{{Allow: false, Path: "/"}},
+		}},
+	}
+}
+
+// Parse разбирает содержимое robots.txt
+func Parse(r io.Reader) (*Robots, error) {
+	robots := &Robots{}
+	scanner := bufio.NewScanner(io.LimitReader(r, maxFileSize))
+	scanner.Buffer(make([]byte, 0, 64*1024), maxFileSize)
+
+	var current *Group
+	// Подряд идущие строки User-agent относятся к одной группе
+	lastWasAgent := false
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			if current == nil || !lastWasAgent {
+				robots.Groups = append(robots.Groups, Group{})
+				current = &robots.Groups[len(robots.Groups)-1]
+			}
+			current.UserAgents = append(current.UserAgents, strings.ToLower(value))
+			lastWasAgent = true
+			continue
+		case "allow", "disallow":
+			if current != nil {
+				// Пустой Disallow означает отсутствие ограничений
+				if value != "" {
+					current.Rules = append(current.Rules, Rule{Allow: key == "allow", Path: escapePath(value)})
+				}
+			}
+		case "crawl-delay":
+			if current != nil {
+				if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds > 0 {
+					current.CrawlDelay = time.Duration(seconds * float64(time.Second))
+				}
+			}
+		case "sitemap":
+			if value != "" {
+				robots.Sitemaps = append(robots.Sitemaps, value)
+			}
+		}
+		lastWasAgent = false
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return robots, nil
+}
+
+// groupsFor возвращает группы, применимые к user-agent'у.
+// Имена групп сравниваются с токеном продукта user-agent'а без учета регистра (RFC 9309),
+// при отсутствии совпадений выбираются группы для "*".
+func (r *Robots) groupsFor(userAgent string) []*Group {
+	token := productToken(userAgent)
+
+	var matched, wildcard []*Group
+	for i := range r.Groups {
+		group := &r.Groups[i]
+		if group.hasAgent(token) {
+			matched = append(matched, group)
+		} else if group.hasAgent("*") {
+			wildcard = append(wildcard, group)
+		}
+	}
+
+	if len(matched) > 0 {
+		return matched
+	}
+	return wildcard
+}
+
+// hasAgent проверяет, что группа относится к токену продукта или к "*"
+func (g *Group) hasAgent(token string) bool {
+	if token == "" {
+		return false
+	}
+	for _, agent := range g.UserAgents {
+		if agent == token || (agent != "*" && productToken(agent) == token) {
+			return true
+		}
+	}
+	return false
+}
+
+// productToken возвращает токен продукта в нижнем регистре — начало строки из букв, "_" и "-".
+// Для "RankVision Bot/1.0" и "RankVision/1.0 (+https://rank-vision.com)" это "rankvision".
+func productToken(userAgent string) string {
+	end := strings.IndexFunc(userAgent, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r == '_' || r == '-')
+	})
+	if end >= 0 {
+		userAgent = userAgent[:end]
+	}
+	return strings.ToLower(userAgent)
+}
+
+// Test проверяет, разрешен ли путь для user-agent'а.
+// path должен содержать путь и строку запроса (например, "/search?q=go").
+// Возвращает сработавшее правило, если оно есть.
+func (r *Robots) Test(userAgent, path string) (bool, *Rule) {
+	if path == "" {
+		path = "/"
+	}
+	// robots.txt никогда не запрещает сам себя
+	if path == "/robots.txt" {
+		return true, nil
+	}
+
+	path = escapePath(path)
+
+	var best *Rule
+	bestLen := -1
+	for _, group := range r.groupsFor(userAgent) {
+		for i := range group.Rules {
+			rule := &group.Rules[i]
+			if !match(rule.Path, path) {
+				continue
+			}
+			// Побеждает самое длинное правило, при равной длине — Allow
+			if len(rule.Path) > bestLen || (len(rule.Path) == bestLen && rule.Allow && !best.Allow) {
+				best = rule
+				bestLen = len(rule.Path)
+			}
+		}
+	}
+
+	if best == nil {
+		return true, nil
+	}
+	return best.Allow, best
+}
+
+// CrawlDelay возвращает Crawl-delay для user-agent'а (0, если не задан)
+func (r *Robots) CrawlDelay(userAgent string) time.Duration {
+	var delay time.Duration
+	for _, group := range r.groupsFor(userAgent) {
+		if group.CrawlDelay > delay {
+			delay = group.CrawlDelay
+		}
+	}
+	return delay
+}
+
+// escapePath приводит путь к виду из URL запроса (RFC 9309, раздел 2.2.2): символы вне ASCII,
+// пробелы и другие недопустимые в пути символы кодируются как %XX, существующие коды
+// переводятся в верхний регистр. Так /корзина совпадает с /%D0%BA%D0%BE%D1%80%D0%B7%D0%B8%D0%BD%D0%B0.
+func escapePath(path string) string {
+	const hex = "0123456789ABCDEF"
+	var b strings.Builder
+	for i := 0; i < len(path); i++ {
+		c := path[i]
+		switch {
+		case c == '%' && i+2 < len(path) && isHex(path[i+1]) && isHex(path[i+2]):
+			b.WriteByte('%')
+			b.WriteString(strings.ToUpper(path[i+1 : i+3]))
+			i += 2
+		case c <= ' ' || c >= 0x7F || strings.IndexByte(`"<>\^`+"`"+`{|}`, c) >= 0:
+			b.WriteByte('%')
+			b.WriteByte(hex[c>>4])
+			b.WriteByte(hex[c&0x0F])
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// isHex проверяет, что байт является шестнадцатеричной цифрой
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
+
+// match проверяет путь на соответствие шаблону с поддержкой "*" и "$"
+func match(pattern, path string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	if anchored {
+		pattern = strings.TrimSuffix(pattern, "$")
+	}
+
+	parts := strings.Split(pattern, "*")
+	// Первая часть должна совпадать с началом пути
+	if !strings.HasPrefix(path, parts[0]) {
+		return false
+	}
+	rest := path[len(parts[0]):]
+
+	for i := 1; i < len(parts); i++ {
+		part := parts[i]
+		if i == len(parts)-1 && anchored {
+			return strings.HasSuffix(rest, part)
+		}
+		idx := strings.Index(rest, part)
+		if idx < 0 {
+			return false
+		}
+		rest = rest[idx+len(part):]
+	}
+
+	return !anchored || rest == ""
+}
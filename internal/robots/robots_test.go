@@ -0,0 +1,287 @@
+package robots
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const testRobots = `
+# Комментарий
+User-agent: *
+Disallow: /admin
+Disallow: /*.pdf$
+Allow: /admin/public
+Crawl-delay: 2
+
+User-agent: RankVision
+User-agent: OtherBot
+Disallow: /private
+Allow: /private/open$
+Crawl-delay: 0.5
+
+Sitemap: https://example.com/sitemap.xml
+Sitemap: https://example.com/news-sitemap.xml
+`
+
+func TestParse(t *testing.T) {
+	robots, err := Parse(strings.NewReader(testRobots))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if len(robots.Groups) != 2 {
+		t.Fatalf("Expected 2 groups, got %d", len(robots.Groups))
+	}
+	if len(robots.Groups[1].UserAgents) != 2 {
+		t.Errorf("Expected 2 user agents in second group, got %d", len(robots.Groups[1].UserAgents))
+	}
+	if len(robots.Sitemaps) != 2 {
+		t.Errorf("Expected 2 sitemaps, got %d", len(robots.Sitemaps))
+	}
+	if got := robots.CrawlDelay("Mozilla/5.0"); got != 2*time.Second {
+		t.Errorf("Expected crawl delay 2s for *, got %v", got)
+	}
+	if got := robots.CrawlDelay("RankVision Bot/1.0"); got != 500*time.Millisecond {
+		t.Errorf("Expected crawl delay 500ms for RankVision, got %v", got)
+	}
+}
+
+func TestRobots_Test(t *testing.T) {
+	robots, err := Parse(strings.NewReader(testRobots))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		userAgent string
+		path      string
+		expected  bool
+	}{
+		{"root allowed", "Mozilla/5.0", "/", true},
+		{"disallowed prefix", "Mozilla/5.0", "/admin/users", false},
+		{"longer allow wins", "Mozilla/5.0", "/admin/public/page", true},
+		{"wildcard with end anchor", "Mozilla/5.0", "/files/report.pdf", false},
+		{"end anchor not matched", "Mozilla/5.0", "/files/report.pdf?download=1", true},
+		{"specific group ignores wildcard group", "RankVision Bot/1.0", "/admin", true},
+		{"specific group disallow", "RankVision Bot/1.0", "/private/data", false},
+		{"specific group exact allow", "RankVision Bot/1.0", "/private/open", true},
+		{"specific group exact allow mismatch", "RankVision Bot/1.0", "/private/open/more", false},
+		{"robots.txt always allowed", "RankVision Bot/1.0", "/robots.txt", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allowed, _ := robots.Test(tt.userAgent, tt.path)
+			if allowed != tt.expected {
+				t.Errorf("Test(%s, %s) = %v; want %v", tt.userAgent, tt.path, allowed, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRobots_Test_PercentEncoding(t *testing.T) {
+	robots, err := Parse(strings.NewReader(`
+User-agent: *
+Disallow: /корзина
+Disallow: /%d0%bf%d1%80%d0%be%d1%84%d0%b8%d0%bb%d1%8c
+Allow: /корзина/общая
+Disallow: /search?q=книги
+`))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	tests := []struct {
+		url      string
+		expected bool
+	}{
+		{"https://example.com/корзина", false},
+		{"https://example.com/%D0%BA%D0%BE%D1%80%D0%B7%D0%B8%D0%BD%D0%B0/1", false},
+		{"https://example.com/корзина/общая", true},
+		{"https://example.com/профиль", false},
+		{"https://example.com/%D0%9F%D1%80%D0%BE%D1%84%D0%B8%D0%BB%D1%8C", true},
+		{"https://example.com/search?q=книги", false},
+		{"https://example.com/catalog", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			u, err := url.Parse(tt.url)
+			if err != nil {
+				t.Fatalf("url.Parse failed: %v", err)
+			}
+			if allowed, _ := robots.Test("RankVision Bot/1.0", u.RequestURI()); allowed != tt.expected {
+				t.Errorf("Test(%s) = %v; want %v", u.RequestURI(), allowed, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRobots_GroupsFor(t *testing.T) {
+	robots, err := Parse(strings.NewReader(`
+User-agent: *
+Disallow: /all
+
+User-agent: Bot
+Disallow: /bot
+
+User-agent: rankvision-news
+Disallow: /news
+
+User-agent: RANKVISION/2.0
+Disallow: /rankvision
+`))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	tests := []struct {
+		userAgent string
+		expected  string
+	}{
+		{"RankVision Bot/1.0", "/rankvision"},
+		{"rankvision/1.0 (+https://rank-vision.com)", "/rankvision"},
+		{"RankVision-News/1.0", "/news"},
+		// Имя группы, входящее в user-agent подстрокой, не совпадает с токеном продукта
+		{"Mozilla/5.0 (compatible; Bot/1.0)", "/all"},
+		{"OtherBot/1.0", "/all"},
+		{"", "/all"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.userAgent, func(t *testing.T) {
+			groups := robots.groupsFor(tt.userAgent)
+			if len(groups) != 1 || len(groups[0].Rules) != 1 || groups[0].Rules[0].Path != tt.expected {
+				t.Errorf("Expected group with Disallow: %s, got %+v", tt.expected, groups)
+			}
+		})
+	}
+}
+
+func TestCache_Test(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			atomic.AddInt32(&requests, 1)
+			w.Write([]byte("User-agent: *\nDisallow: /secret\n"))
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	cache := NewCache(server.Client(), "RankVision Bot/1.0")
+
+	allowed, _, err := cache.Test(context.Background(), server.URL+"/page")
+	if err != nil {
+		t.Fatalf("Test failed: %v", err)
+	}
+	if !allowed {
+		t.Errorf("Expected /page to be allowed")
+	}
+
+	allowed, rule, err := cache.Test(context.Background(), server.URL+"/secret/file")
+	if err != nil {
+		t.Fatalf("Test failed: %v", err)
+	}
+	if allowed {
+		t.Errorf("Expected /secret/file to be disallowed")
+	}
+	if rule == nil || rule.String() != "Disallow: /secret" {
+		t.Errorf("Expected rule 'Disallow: /secret', got %v", rule)
+	}
+
+	if atomic.LoadInt32(&requests) != 1 {
+		t.Errorf("Expected robots.txt to be fetched once, got %d", requests)
+	}
+}
+
+func TestCache_StatusCodes(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		expected bool
+	}{
+		{"not found allows all", http.StatusNotFound, true},
+		{"forbidden allows all", http.StatusForbidden, true},
+		{"server error disallows all", http.StatusServiceUnavailable, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			cache := NewCache(server.Client(), "RankVision Bot/1.0")
+			allowed, _, err := cache.Test(context.Background(), server.URL+"/page")
+			if err != nil {
+				t.Fatalf("Test failed: %v", err)
+			}
+			if allowed != tt.expected {
+				t.Errorf("Expected allowed=%v for status %d, got %v", tt.expected, tt.status, allowed)
+			}
+		})
+	}
+}
+
+func TestCache_Canceled(t *testing.T) {
+	var requests int32
+	started := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			// Первая загрузка зависает до отмены запроса
+			close(started)
+			<-r.Context().Done()
+			return
+		}
+		w.Write([]byte("User-agent: *\nDisallow: /secret\n"))
+	}))
+	defer server.Close()
+
+	cache := NewCache(server.Client(), "RankVision Bot/1.0")
+	ctx, cancel := context.WithCancel(context.Background())
+
+	leaderErr := make(chan error, 1)
+	go func() {
+		_, _, err := cache.Test(ctx, server.URL+"/page")
+		leaderErr <- err
+	}()
+	<-started
+
+	// Ожидающий запрос с активным контекстом получает ошибку прерванной загрузки, а не полный запрет
+	waiterErr := make(chan error, 1)
+	go func() {
+		_, _, err := cache.Test(context.Background(), server.URL+"/page")
+		waiterErr <- err
+	}()
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+
+	if err := <-leaderErr; err != context.Canceled {
+		t.Errorf("Expected leader error %v, got %v", context.Canceled, err)
+	}
+	if err := <-waiterErr; err != context.Canceled {
+		t.Errorf("Expected waiter error %v, got %v", context.Canceled, err)
+	}
+
+	// Прерванная загрузка не кэшируется: следующий запрос загружает robots.txt заново
+	allowed, _, err := cache.Test(context.Background(), server.URL+"/page")
+	if err != nil {
+		t.Fatalf("Test failed: %v", err)
+	}
+	if !allowed {
+		t.Error("Expected /page to be allowed after refetch")
+	}
+	if got := atomic.LoadInt32(&requests); got != 2 {
+		t.Errorf("Expected robots.txt to be fetched twice, got %d", got)
+	}
+}
@@ -0,0 +1,135 @@
+package sitemap
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+const (
+	// maxIndexDepth ограничивает вложенность индексных файлов
+	maxIndexDepth = 2
+
+	// maxSitemaps ограничивает количество загружаемых файлов sitemap
+	maxSitemaps = 1000
+)
+
+// Result содержит все найденные sitemap и их URL
+type Result struct {
+	// Sitemaps содержит успешно обработанные файлы
+	Sitemaps []string
+	// URLs содержит уникальные URL из всех файлов
+	URLs []URL
+	// Errors содержит ошибки загрузки или разбора файлов
+	Errors map[string]error
+}
+
+// Fetcher загружает и разбирает sitemap
+type Fetcher struct {
+	client    *http.Client
+	userAgent string
+}
+
+// NewFetcher создает новый экземпляр Fetcher
+func NewFetcher(client *http.Client, userAgent string) *Fetcher {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &Fetcher{
+		client:    client,
+		userAgent: userAgent,
+	}
+}
+
+// Fetch загружает и разбирает один файл sitemap
+func (f *Fetcher) Fetch(ctx context.Context, sitemapURL string) (*Document, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", sitemapURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", f.userAgent)
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	return Parse(resp.Body)
+}
+
+// Discover находит sitemap сайта и собирает их URL.
+// Проверяются файлы, объявленные в robots.txt, и стандартный /sitemap.xml;
+// индексные файлы обходятся рекурсивно.
+func (f *Fetcher) Discover(ctx context.Context, baseURL *url.URL, declared []string) *Result {
+	result := &Result{
+		Errors: make(map[string]error),
+	}
+
+	type candidate struct {
+		url     string
+		depth   int
+		guessed bool
+	}
+
+	var queue []candidate
+	for _, loc := range declared {
+		queue = append(queue, candidate{url: loc})
+	}
+	defaultURL := baseURL.ResolveReference(&url.URL{Path: "/sitemap.xml"}).String()
+	queue = append(queue, candidate{url: defaultURL, guessed: true})
+
+	seenSitemaps := make(map[string]bool)
+	seenURLs := make(map[string]bool)
+
+	for len(queue) > 0 && len(seenSitemaps) < maxSitemaps {
+		current := queue[0]
+		queue = queue[1:]
+
+		if seenSitemaps[current.url] {
+			continue
+		}
+		seenSitemaps[current.url] = true
+
+		if ctx.Err() != nil {
+			break
+		}
+
+		doc, err := f.Fetch(ctx, current.url)
+		if err != nil {
+			// Отсутствие sitemap по стандартному адресу ошибкой не считаем
+			if !current.guessed {
+				result.Errors[current.url] = err
+			}
+			continue
+		}
+		result.Sitemaps = append(result.Sitemaps, current.url)
+
+		if doc.IsIndex() {
+			if current.depth >= maxIndexDepth {
+				result.Errors[current.url] = fmt.Errorf("sitemap index nesting exceeds %d levels", maxIndexDepth)
+				continue
+			}
+			for _, loc := range doc.Sitemaps {
+				queue = append(queue, candidate{url: loc, depth: current.depth + 1})
+			}
+			continue
+		}
+
+		for _, u := range doc.URLs {
+			if seenURLs[u.Loc] {
+				continue
+			}
+			seenURLs[u.Loc] = true
+			u.Sitemap = current.url
+			result.URLs = append(result.URLs, u)
+		}
+	}
+
+	return result
+}
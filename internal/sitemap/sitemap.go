@@ -0,0 +1,127 @@
+package sitemap
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/xml"
+	"io"
+	"strings"
+)
+
+// maxFileSize ограничивает размер распакованного sitemap (лимит протокола — 50 МБ)
+const maxFileSize = 50 * 1024 * 1024
+
+// URL представляет запись <url> из sitemap
+type URL struct {
+	Loc        string
+	LastMod    string
+	ChangeFreq string
+	Priority   string
+	// Alternates содержит hreflang-альтернативы из элементов <xhtml:link rel="alternate">
+	Alternates []Alternate
+	// Sitemap содержит адрес файла, в котором найдена запись
+	Sitemap string
+}
+
+// Alternate представляет языковую версию страницы из sitemap
+type Alternate struct {
+	// Hreflang — код языка и региона (ru, kk-KZ, x-default)
+	Hreflang string
+	Href     string
+}
+
+// Document представляет разобранный файл sitemap.
+// Для индексного файла заполнено поле Sitemaps, для обычного — URLs.
+type Document struct {
+	URLs     []URL
+	Sitemaps []string
+}
+
+// IsIndex возвращает true для индексного файла sitemap
+func (d *Document) IsIndex() bool {
+	return len(d.Sitemaps) > 0
+}
+
+// xmlDocument описывает корневые элементы urlset и sitemapindex
+type xmlDocument struct {
+	XMLName  xml.Name
+	URLs     []xmlURL     `xml:"url"`
+	Sitemaps []xmlSitemap `xml:"sitemap"`
+}
+
+type xmlURL struct {
+	Loc        string `xml:"loc"`
+	LastMod    string `xml:"lastmod"`
+	ChangeFreq string `xml:"changefreq"`
+	Priority   string `xml:"priority"`
+	// Links содержит элементы <xhtml:link> в пространстве имен XHTML
+	Links []xmlLink `xml:"http://www.w3.org/1999/xhtml link"`
+}
+
+type xmlLink struct {
+	Rel      string `xml:"rel,attr"`
+	Hreflang string `xml:"hreflang,attr"`
+	Href     string `xml:"href,attr"`
+}
+
+type xmlSitemap struct {
+	Loc string `xml:"loc"`
+}
+
+// Parse разбирает sitemap в формате XML. Сжатые gzip файлы распаковываются автоматически.
+func Parse(r io.Reader) (*Document, error) {
+	br := bufio.NewReader(r)
+
+	// Определяем gzip по сигнатуре, а не по расширению или заголовкам
+	if magic, err := br.Peek(2); err == nil && bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		r = gz
+	} else {
+		r = br
+	}
+
+	var doc xmlDocument
+	decoder := xml.NewDecoder(io.LimitReader(r, maxFileSize))
+	// Многие sitemap объявляют кодировку, отличную от UTF-8, хотя фактически используют ее
+	decoder.CharsetReader = func(_ string, input io.Reader) (io.Reader, error) {
+		return input, nil
+	}
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	result := &Document{}
+	for _, u := range doc.URLs {
+		loc := strings.TrimSpace(u.Loc)
+		if loc == "" {
+			continue
+		}
+		entry := URL{
+			Loc:        loc,
+			LastMod:    strings.TrimSpace(u.LastMod),
+			ChangeFreq: strings.TrimSpace(u.ChangeFreq),
+			Priority:   strings.TrimSpace(u.Priority),
+		}
+		for _, link := range u.Links {
+			hreflang := strings.TrimSpace(link.Hreflang)
+			href := strings.TrimSpace(link.Href)
+			if !strings.EqualFold(strings.TrimSpace(link.Rel), "alternate") || hreflang == "" || href == "" {
+				continue
+			}
+			entry.Alternates = append(entry.Alternates, Alternate{Hreflang: hreflang, Href: href})
+		}
+		result.URLs = append(result.URLs, entry)
+	}
+	for _, s := range doc.Sitemaps {
+		if loc := strings.TrimSpace(s.Loc); loc != "" {
+			result.Sitemaps = append(result.Sitemaps, loc)
+		}
+	}
+
+	return result, nil
+}
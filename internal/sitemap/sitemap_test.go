@@ -0,0 +1,152 @@
+package sitemap
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testURLSet = `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+	<url>
+		<loc> https://example.com/ </loc>
+		<lastmod>2024-01-01</lastmod>
+		<priority>1.0</priority>
+	</url>
+	<url>
+		<loc>https://example.com/about</loc>
+	</url>
+</urlset>`
+
+func gzipBytes(t *testing.T, data string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write([]byte(data)); err != nil {
+		t.Fatalf("gzip write failed: %v", err)
+	}
+	gz.Close()
+	return buf.Bytes()
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name         string
+		data         []byte
+		wantURLs     int
+		wantSitemaps int
+	}{
+		{"urlset", []byte(testURLSet), 2, 0},
+		{"gzip urlset", gzipBytes(t, testURLSet), 2, 0},
+		{"index", []byte(`<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+			<sitemap><loc>https://example.com/a.xml</loc></sitemap>
+			<sitemap><loc>https://example.com/b.xml.gz</loc></sitemap>
+		</sitemapindex>`), 0, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := Parse(bytes.NewReader(tt.data))
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			if len(doc.URLs) != tt.wantURLs {
+				t.Errorf("Expected %d URLs, got %d", tt.wantURLs, len(doc.URLs))
+			}
+			if len(doc.Sitemaps) != tt.wantSitemaps {
+				t.Errorf("Expected %d sitemaps, got %d", tt.wantSitemaps, len(doc.Sitemaps))
+			}
+		})
+	}
+
+	doc, err := Parse(strings.NewReader(testURLSet))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if doc.URLs[0].Loc != "https://example.com/" {
+		t.Errorf("Expected trimmed loc, got '%s'", doc.URLs[0].Loc)
+	}
+	if doc.URLs[0].LastMod != "2024-01-01" {
+		t.Errorf("Expected lastmod '2024-01-01', got '%s'", doc.URLs[0].LastMod)
+	}
+}
+
+func TestParse_Alternates(t *testing.T) {
+	data := `<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9" xmlns:xhtml="http://www.w3.org/1999/xhtml">
+		<url>
+			<loc>https://example.com/ru/</loc>
+			<xhtml:link rel="alternate" hreflang="ru" href="https://example.com/ru/"/>
+			<xhtml:link rel="alternate" hreflang="kk-KZ" href=" https://example.com/kk/ "/>
+			<xhtml:link rel="alternate" hreflang="x-default" href="https://example.com/"/>
+			<xhtml:link rel="canonical" href="https://example.com/ru/"/>
+			<xhtml:link rel="alternate" href="https://example.com/en/"/>
+		</url>
+	</urlset>`
+
+	doc, err := Parse(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(doc.URLs) != 1 {
+		t.Fatalf("Expected 1 URL, got %d", len(doc.URLs))
+	}
+
+	// Ссылки без hreflang и с другим rel пропускаются
+	expected := []Alternate{
+		{Hreflang: "ru", Href: "https://example.com/ru/"},
+		{Hreflang: "kk-KZ", Href: "https://example.com/kk/"},
+		{Hreflang: "x-default", Href: "https://example.com/"},
+	}
+	if !reflect.DeepEqual(doc.URLs[0].Alternates, expected) {
+		t.Errorf("Expected alternates %v, got %v", expected, doc.URLs[0].Alternates)
+	}
+}
+
+func TestFetcher_Discover(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/sitemap_index.xml":
+			w.Write([]byte(`<sitemapindex>
+				<sitemap><loc>` + server.URL + `/pages.xml.gz</loc></sitemap>
+				<sitemap><loc>` + server.URL + `/missing.xml</loc></sitemap>
+			</sitemapindex>`))
+		case "/pages.xml.gz":
+			w.Write(gzipBytes(t, `<urlset>
+				<url><loc>`+server.URL+`/a</loc></url>
+				<url><loc>`+server.URL+`/b</loc></url>
+			</urlset>`))
+		case "/sitemap.xml":
+			w.Write([]byte(`<urlset>
+				<url><loc>` + server.URL + `/b</loc></url>
+				<url><loc>` + server.URL + `/c</loc></url>
+			</urlset>`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	baseURL, _ := url.Parse(server.URL)
+	fetcher := NewFetcher(server.Client(), "RankVision Bot/1.0")
+	result := fetcher.Discover(context.Background(), baseURL, []string{server.URL + "/sitemap_index.xml"})
+
+	if len(result.Sitemaps) != 3 {
+		t.Errorf("Expected 3 processed sitemaps, got %d: %v", len(result.Sitemaps), result.Sitemaps)
+	}
+	if len(result.URLs) != 3 {
+		t.Errorf("Expected 3 unique URLs, got %d", len(result.URLs))
+	}
+	if _, ok := result.Errors[server.URL+"/missing.xml"]; !ok {
+		t.Errorf("Expected error for missing.xml, got %v", result.Errors)
+	}
+	if len(result.Errors) != 1 {
+		t.Errorf("Expected 1 error, got %d: %v", len(result.Errors), result.Errors)
+	}
+}
@@ -20,6 +20,8 @@ type DatabaseConfig struct {
 
 type CrawlerConfig struct {
 	MaxConcurrentRequests int
+	MaxConnsPerHost       int
+	MaxQueueSize          int // 0 — без ограничения
 	UserAgent             string
 	RequestDelay          int // в миллисекундах
 }
@@ -38,6 +40,7 @@ func NewConfig() *Config {
 		},
 		Crawler: CrawlerConfig{
 			MaxConcurrentRequests: 10,
+			MaxConnsPerHost:       2,
 			UserAgent:             "RankVision Bot/1.0",
 			RequestDelay:          1000,
 		},